@@ -0,0 +1,44 @@
+package backoff
+
+import "time"
+
+// Redelivery is the decision of [Policy.Redelivery] for a message that failed
+// processing.
+type Redelivery struct {
+	// Delay is the backoff delay before the message should be processed
+	// again.
+	Delay time.Duration
+
+	// Extend is how much the ack deadline of the message must be extended
+	// to hold it through Delay and the next attempt. It is zero if the
+	// current deadline suffices or if Nack is set.
+	Extend time.Duration
+
+	// Nack reports whether the message should be nacked with a redelivery
+	// delay of Delay, because holding it would require extending its ack
+	// deadline by more than allowed.
+	Nack bool
+}
+
+// Redelivery decides how a handler of a message from a queue or Pub/Sub
+// system backs off after the attempt failed, given the ack or visibility
+// deadline of the message and the largest extension of that deadline the
+// broker allows. If the delay from [Policy.Duration], plus p.DeadlineReserve
+// for the next attempt, fits within the deadline or an allowed extension of
+// it, the handler holds the message, extending its deadline by Extend.
+// Otherwise it nacks the message for redelivery after Delay.
+//
+// The attempt is typically the delivery attempt count of the message minus
+// one, so that the schedule survives redeliveries.
+func (p *Policy) Redelivery(attempt int, deadline time.Time, maxExtension time.Duration) Redelivery {
+	r := Redelivery{Delay: p.Duration(attempt)}
+	need := r.Delay + p.DeadlineReserve - time.Until(deadline)
+	switch {
+	case need <= 0:
+	case need <= maxExtension:
+		r.Extend = need
+	default:
+		r.Nack = true
+	}
+	return r
+}
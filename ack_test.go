@@ -0,0 +1,74 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPolicyRedelivery(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		delay        time.Duration
+		reserve      time.Duration
+		remaining    time.Duration
+		maxExtension time.Duration
+		wantExtend   bool
+		wantNack     bool
+	}{
+		{
+			name:         "FitsDeadline",
+			delay:        time.Second,
+			reserve:      time.Second,
+			remaining:    time.Minute,
+			maxExtension: time.Minute,
+		},
+		{
+			name:         "Extends",
+			delay:        time.Minute,
+			reserve:      time.Second,
+			remaining:    10 * time.Second,
+			maxExtension: 10 * time.Minute,
+			wantExtend:   true,
+		},
+		{
+			name:         "Nacks",
+			delay:        time.Hour,
+			reserve:      time.Second,
+			remaining:    10 * time.Second,
+			maxExtension: 10 * time.Minute,
+			wantNack:     true,
+		},
+		{
+			name:         "ReserveRequiresExtension",
+			delay:        time.Second,
+			reserve:      time.Minute,
+			remaining:    10 * time.Second,
+			maxExtension: 10 * time.Minute,
+			wantExtend:   true,
+		},
+		{
+			name:         "NoExtensionAllowed",
+			delay:        time.Minute,
+			remaining:    10 * time.Second,
+			maxExtension: 0,
+			wantNack:     true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Policy{Replay: []time.Duration{tt.delay}, DeadlineReserve: tt.reserve}
+			r := p.Redelivery(0, time.Now().Add(tt.remaining), tt.maxExtension)
+			if r.Delay != tt.delay {
+				t.Errorf("got delay %v, want %v", r.Delay, tt.delay)
+			}
+			if r.Nack != tt.wantNack {
+				t.Errorf("got nack %t, want %t", r.Nack, tt.wantNack)
+			}
+			if got := r.Extend > 0; got != tt.wantExtend {
+				t.Errorf("got extend %v, want positive %t", r.Extend, tt.wantExtend)
+			}
+			if want := tt.delay + tt.reserve - tt.remaining; tt.wantExtend && (r.Extend < want || r.Extend > want+time.Second) {
+				t.Errorf("got extend %v, want about %v", r.Extend, want)
+			}
+		})
+	}
+}
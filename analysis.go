@@ -0,0 +1,63 @@
+package backoff
+
+import (
+	"math"
+	"time"
+)
+
+// MeanTotalWait returns the expected total time spent waiting between the
+// given number of attempts under p, that is, the sum of the means of the
+// delays after attempts 0 through attempts-2.
+func (p *Policy) MeanTotalWait(attempts int) time.Duration {
+	mean, _, _ := p.totalWaitMoments(attempts)
+	return saturatingDuration(mean)
+}
+
+// TotalWaitQuantile returns an approximation of the q-quantile (0 <= q <= 1)
+// of the total time spent waiting between the given number of attempts under
+// p. For example, q = 0.99 yields a total wait that is exceeded in only about
+// 1% of retry sequences, which is a reasonable basis for timeouts and alert
+// thresholds.
+//
+// The total wait is a sum of independent uniform delays, which is
+// approximated by a normal distribution clamped to the possible range. The
+// approximation improves with the number of attempts.
+func (p *Policy) TotalWaitQuantile(attempts int, q float64) time.Duration {
+	mean, variance, maximum := p.totalWaitMoments(attempts)
+	switch {
+	case q <= 0:
+		return 0
+	case q >= 1:
+		return saturatingDuration(maximum)
+	}
+	z := math.Sqrt2 * math.Erfinv(2*q-1)
+	return saturatingDuration(min(max(mean+z*math.Sqrt(variance), 0), maximum))
+}
+
+// totalWaitMoments returns the mean, variance and maximum, in nanoseconds, of
+// the total time spent waiting between the given number of attempts under p.
+func (p *Policy) totalWaitMoments(attempts int) (mean, variance, maximum float64) {
+	for attempt := range max(attempts-1, 0) {
+		lo, hi := p.DelayBounds(attempt)
+
+		// A delay drawn uniformly from the n integers in [lo, hi] has a
+		// mean of (lo+hi)/2 and a variance of (n^2-1)/12.
+		n := float64(hi-lo) + 1
+		mean += (float64(lo) + float64(hi)) / 2
+		variance += (n*n - 1) / 12
+		maximum += float64(hi)
+	}
+	return mean, variance, maximum
+}
+
+// saturatingDuration converts nanoseconds to a [time.Duration], saturating at
+// the bounds of the representable durations instead of overflowing.
+func saturatingDuration(ns float64) time.Duration {
+	switch {
+	case ns >= math.MaxInt64:
+		return math.MaxInt64
+	case ns <= math.MinInt64:
+		return math.MinInt64
+	}
+	return time.Duration(ns)
+}
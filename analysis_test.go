@@ -0,0 +1,80 @@
+package backoff
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPolicyMeanTotalWait(t *testing.T) {
+	p := &Policy{Base: 100 * time.Millisecond, Cap: 300 * time.Millisecond}
+	for _, tt := range []struct {
+		name     string
+		attempts int
+		want     time.Duration
+	}{
+		{
+			name:     "NoAttempts",
+			attempts: 0,
+			want:     0,
+		},
+		{
+			name:     "SingleAttempt",
+			attempts: 1,
+			want:     0,
+		},
+		{
+			name:     "FourAttempts",
+			attempts: 4,
+			want:     (100*time.Millisecond - 1 + 200*time.Millisecond - 1 + 300*time.Millisecond - 1) / 2,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.MeanTotalWait(tt.attempts); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("Saturates", func(t *testing.T) {
+		p := &Policy{Base: time.Duration(math.MaxInt64), Cap: time.Duration(math.MaxInt64)}
+		if got := p.MeanTotalWait(10); got != math.MaxInt64 {
+			t.Errorf("got %v, want %v", got, time.Duration(math.MaxInt64))
+		}
+	})
+}
+
+func TestPolicyTotalWaitQuantile(t *testing.T) {
+	p := &Policy{Base: 100 * time.Millisecond, Cap: 10 * time.Second}
+	attempts := 10
+
+	mean := p.MeanTotalWait(attempts)
+	if got := p.TotalWaitQuantile(attempts, 0.5); got != mean {
+		t.Errorf("got median %v, want %v", got, mean)
+	}
+	if got := p.TotalWaitQuantile(attempts, 0); got != 0 {
+		t.Errorf("got %v, want 0", got)
+	}
+
+	p99 := p.TotalWaitQuantile(attempts, 0.99)
+	maximum := p.TotalWaitQuantile(attempts, 1)
+	if p99 <= mean || p99 > maximum {
+		t.Errorf("got p99 %v, want range (%v, %v]", p99, mean, maximum)
+	}
+
+	// Compare the approximation against an empirical quantile.
+	const n = 2000
+	var exceeded int
+	for range n {
+		var total time.Duration
+		for _, d := range p.Plan(attempts - 1) {
+			total += d
+		}
+		if total > p99 {
+			exceeded++
+		}
+	}
+	if got := float64(exceeded) / n; got > 0.03 {
+		t.Errorf("got %.3f of totals above p99, want <= 0.03", got)
+	}
+}
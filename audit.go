@@ -0,0 +1,44 @@
+package backoff
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// AuditLog returns a function for [Policy.Observe] that writes every
+// [RetryEvent] of the named operation to w as a line of JSON, for an audit
+// trail of retries. Write errors are ignored. The returned function is safe for
+// concurrent use, serializing its writes to w.
+func AuditLog(w io.Writer, operation string) func(e RetryEvent) {
+	var mu sync.Mutex
+	return func(e RetryEvent) {
+		var errText string
+		if e.Err != nil {
+			errText = e.Err.Error()
+		}
+		b, _ := json.Marshal(struct {
+			Time      time.Time `json:"time"`
+			Operation string    `json:"operation"`
+			Attempt   int       `json:"attempt"`
+			Took      string    `json:"took"`
+			Delay     string    `json:"delay"`
+			Error     string    `json:"error,omitempty"`
+			Outcome   string    `json:"outcome"`
+		}{
+			Time:      time.Now().UTC(),
+			Operation: operation,
+			Attempt:   e.Attempt,
+			Took:      e.Took.String(),
+			Delay:     e.Delay.String(),
+			Error:     errText,
+			Outcome:   e.Outcome.String(),
+		})
+		b = append(b, '\n')
+
+		mu.Lock()
+		defer mu.Unlock()
+		w.Write(b)
+	}
+}
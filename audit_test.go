@@ -0,0 +1,56 @@
+package backoff
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuditLog(t *testing.T) {
+	var buf bytes.Buffer
+	p := &Policy{
+		Base:        time.Second,
+		Cap:         time.Second,
+		MaxAttempts: 2,
+		Waiter:      &recordingWaiter{},
+		Observe:     AuditLog(&buf, "fetch"),
+	}
+	p.Retry(context.Background(), func(context.Context) error { return errors.New("failed") })
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+	for i, wantOutcome := range []string{"retry", "exhausted"} {
+		var got struct {
+			Time      time.Time `json:"time"`
+			Operation string    `json:"operation"`
+			Attempt   int       `json:"attempt"`
+			Delay     string    `json:"delay"`
+			Error     string    `json:"error"`
+			Outcome   string    `json:"outcome"`
+		}
+		if err := json.Unmarshal([]byte(lines[i]), &got); err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		if got.Time.IsZero() {
+			t.Error("got zero time")
+		}
+		if got.Operation != "fetch" {
+			t.Errorf("got operation %q, want %q", got.Operation, "fetch")
+		}
+		if got.Attempt != i {
+			t.Errorf("got attempt %d, want %d", got.Attempt, i)
+		}
+		if got.Error != "failed" {
+			t.Errorf("got error %q, want %q", got.Error, "failed")
+		}
+		if got.Outcome != wantOutcome {
+			t.Errorf("got outcome %q, want %q", got.Outcome, wantOutcome)
+		}
+	}
+}
@@ -1,86 +1,485 @@
 /*
-Package backoff implements a Full-Jitter exponential backoff helper for Go.
+Package backoff implements a jittered exponential backoff helper for Go.
+
+# Strict mode
+
+Functions such as [Duration] treat impossible parameters (a non-positive base
+or cap, or a negative attempt) as "no delay", which can silently disable
+backoff. Building with the backoff_strict tag makes them panic instead, which
+is useful for catching call-site bugs in tests:
+
+	go test -tags backoff_strict ./...
 */
 package backoff
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"iter"
+	"math"
 	"math/rand/v2"
+	"sync"
 	"time"
 )
 
+// Errors returned by the strict variants of the package's functions, such as
+// [DurationE] and [AttemptsE], for invalid parameters.
+var (
+	ErrInvalidBase        = errors.New("backoff: base must be positive")
+	ErrInvalidCap         = errors.New("backoff: cap must be positive")
+	ErrBaseExceedsCap     = errors.New("backoff: base must not exceed cap")
+	ErrInvalidAttempt     = errors.New("backoff: attempt must not be negative")
+	ErrInvalidMaxAttempts = errors.New("backoff: maxAttempts must be positive")
+	ErrInvalidMultiplier  = errors.New("backoff: multiplier must be at least 1")
+	ErrInvalidJitter      = errors.New("backoff: unknown jitter strategy")
+)
+
 // Duration returns a randomized exponential-backoff delay. The delay is chosen
 // uniformly from [0, min(cap, base*2^attempt)).
 func Duration(base, cap time.Duration, attempt int) time.Duration {
+	return time.Duration(DurationNanos(int64(base), int64(cap), attempt))
+}
+
+// DurationNanos is like [Duration] but takes and returns integer nanoseconds.
+// It is intended for hot paths that already work in nanoseconds.
+func DurationNanos(base, cap int64, attempt int) int64 {
 	if base <= 0 || cap <= 0 || attempt < 0 {
+		checkStrict(time.Duration(base), time.Duration(cap), attempt)
 		return 0
 	}
+	if limit := limitNanos(base, cap, attempt); limit > 1 {
+		return rand.N(limit)
+	}
+	return 0
+}
 
-	// Limit = base * 2^attempt, but never above cap and never overflow.
-	var limit time.Duration
-	if attempt >= 63 || base > cap>>attempt {
-		limit = cap
-	} else {
-		limit = base << attempt
+// DurationWithLimit is like [Duration] but also returns the limit the delay was
+// drawn from, that is, min(cap, base*2^attempt). The limit is 0 for invalid
+// parameters.
+func DurationWithLimit(base, cap time.Duration, attempt int) (delay, limit time.Duration) {
+	if base <= 0 || cap <= 0 || attempt < 0 {
+		checkStrict(base, cap, attempt)
+		return 0, 0
 	}
+	limit = time.Duration(limitNanos(int64(base), int64(cap), attempt))
+	if limit > 1 {
+		delay = time.Duration(rand.N(int64(limit)))
+	}
+	return delay, limit
+}
 
-	if limit <= 1 {
+// DurationCapped is like [Duration] but also reports whether the limit the
+// delay was drawn from has reached cap, meaning that the backoff has
+// saturated and later attempts will not wait any longer.
+func DurationCapped(base, cap time.Duration, attempt int) (d time.Duration, capped bool) {
+	if base <= 0 || cap <= 0 || attempt < 0 {
+		checkStrict(base, cap, attempt)
+		return 0, false
+	}
+	limit := limitNanos(int64(base), int64(cap), attempt)
+	if limit > 1 {
+		d = time.Duration(rand.N(limit))
+	}
+	return d, limit == int64(cap)
+}
+
+// DurationE is like [Duration] but returns an error wrapping one of
+// [ErrInvalidBase], [ErrInvalidCap], [ErrBaseExceedsCap] or [ErrInvalidAttempt]
+// for invalid parameters instead of silently returning 0 or flattening the
+// curve to cap.
+func DurationE(base, cap time.Duration, attempt int) (time.Duration, error) {
+	if err := validate(base, cap); err != nil {
+		return 0, err
+	}
+	if attempt < 0 {
+		return 0, fmt.Errorf("%w: got %d", ErrInvalidAttempt, attempt)
+	}
+	return Duration(base, cap, attempt), nil
+}
+
+// DurationElapsed returns a randomized backoff delay whose limit grows with the
+// time elapsed since the first failure rather than with an attempt counter.
+// The delay is chosen uniformly from [0, min(cap, max(base, elapsed))), so
+// operations with highly variable attempt durations still back off in
+// proportion to how long they have been failing.
+func DurationElapsed(base, cap, elapsed time.Duration) time.Duration {
+	if base <= 0 || cap <= 0 {
+		checkStrict(base, cap, 0)
+		return 0
+	}
+	if limit := min(cap, max(base, elapsed)); limit > 1 {
+		return time.Duration(rand.N(int64(limit)))
+	}
+	return 0
+}
+
+// DurationAt is like [Duration] but takes a fractional attempt, drawing the
+// delay from [0, min(cap, base*2^attempt)). It allows adaptive systems to
+// adjust their effective attempt level continuously rather than in integer
+// steps. It returns 0 for invalid parameters, including a NaN attempt, which
+// strict mode reports as such rather than as a negative attempt.
+func DurationAt(base, cap time.Duration, attempt float64) time.Duration {
+	if base <= 0 || cap <= 0 || !(attempt >= 0) {
+		checkStrict(base, cap, 0)
+		if strict && !(attempt >= 0) {
+			panic(fmt.Errorf("%w: got %v", ErrInvalidAttempt, attempt))
+		}
 		return 0
 	}
-	return time.Duration(rand.N(int64(limit)))
+	limit := cap
+	if l := float64(base) * math.Exp2(attempt); l < float64(cap) {
+		limit = time.Duration(l)
+	}
+	return time.Duration(randN(nil, int64(limit)))
 }
 
-// Sleep blocks for the delay produced by [Duration]. It is shorthand for
-// time.Sleep(Duration(base, cap, attempt)).
+// DurationRange is like [Duration] but never returns less than min, that is,
+// the delay is chosen uniformly from [min, min(cap, base*2^attempt)) so that
+// early retries do not arrive within microseconds of a failure. It returns min
+// when the limit does not exceed it, and 0 for invalid parameters.
+func DurationRange(min, base, cap time.Duration, attempt int) time.Duration {
+	if base <= 0 || cap <= 0 || attempt < 0 {
+		checkStrict(base, cap, attempt)
+		return 0
+	}
+	min = max(min, 0)
+	limit := time.Duration(limitNanos(int64(base), int64(cap), attempt))
+	if limit <= min {
+		return min
+	}
+	return min + time.Duration(randN(nil, int64(limit-min)))
+}
+
+// DurationFactor is like [Duration] but grows the limit by factor instead of
+// doubling it, that is, the delay is chosen uniformly from
+// [0, min(cap, base*factor^attempt)). A factor of 1 keeps the limit at base.
+// It returns 0 for invalid parameters, including a factor below 1.
+func DurationFactor(base, cap time.Duration, factor float64, attempt int) time.Duration {
+	if base <= 0 || cap <= 0 || attempt < 0 {
+		checkStrict(base, cap, attempt)
+		return 0
+	}
+	if err := validateMultiplier(factor); err != nil {
+		if strict {
+			panic(err)
+		}
+		return 0
+	}
+	return time.Duration(randN(nil, factorLimitNanos(int64(base), int64(cap), factor, attempt)))
+}
+
+// MaxDuration returns the largest delay [Duration] can return for the
+// parameters, that is, one nanosecond less than min(cap, base*2^attempt), so
+// that timeouts around [Sleep] and [After] can be set without duplicating the
+// limit computation. It returns 0 for invalid parameters.
+func MaxDuration(base, cap time.Duration, attempt int) time.Duration {
+	if base <= 0 || cap <= 0 || attempt < 0 {
+		checkStrict(base, cap, attempt)
+		return 0
+	}
+	return time.Duration(max(limitNanos(int64(base), int64(cap), attempt)-1, 0))
+}
+
+// TotalMaxWait returns the worst-case total time spent waiting between
+// maxAttempts attempts, that is, the sum of [MaxDuration] for attempts 0
+// through maxAttempts-2, saturating instead of overflowing. It is intended for
+// sizing upstream deadlines. It returns 0 for invalid parameters or if
+// maxAttempts < 2.
+func TotalMaxWait(base, cap time.Duration, maxAttempts int) time.Duration {
+	if base <= 0 || cap <= 0 {
+		checkStrict(base, cap, 0)
+		return 0
+	}
+	n := max(maxAttempts-1, 0)
+	steps := min(n, stepsToCap(int64(base), int64(cap), 2))
+	return sumDelays(n, steps, func(attempt int) time.Duration {
+		return MaxDuration(base, cap, attempt)
+	})
+}
+
+// sumDelays returns delay(0) + delay(1) + ... + delay(n-1), saturating at
+// math.MaxInt64, for non-negative delays that stay the same from attempt
+// steps on, as they do once the steps to cap are taken. The attempts from
+// steps on are summed in one step, so n may be huge.
+func sumDelays(n, steps int, delay func(attempt int) time.Duration) time.Duration {
+	var total time.Duration
+	for attempt := range steps {
+		d := delay(attempt)
+		if total > math.MaxInt64-d {
+			return math.MaxInt64
+		}
+		total += d
+	}
+	if rest := n - steps; rest > 0 {
+		d := delay(steps)
+		if d > 0 && rest > int((math.MaxInt64-total)/d) {
+			return math.MaxInt64
+		}
+		total += time.Duration(rest) * d
+	}
+	return total
+}
+
+// TotalMaxWaitWithTimeout is like [TotalMaxWait] but also includes
+// maxAttempts attempts taking up to attemptTimeout each, which is the
+// worst-case duration of the whole retry sequence.
+func TotalMaxWaitWithTimeout(base, cap time.Duration, maxAttempts int, attemptTimeout time.Duration) time.Duration {
+	total := TotalMaxWait(base, cap, maxAttempts)
+	if maxAttempts <= 0 || attemptTimeout <= 0 {
+		return total
+	}
+	if attemptTimeout > (math.MaxInt64-total)/time.Duration(maxAttempts) {
+		return math.MaxInt64
+	}
+	return total + time.Duration(maxAttempts)*attemptTimeout
+}
+
+// Fill fills dst with the delays produced by [Duration] for len(dst)
+// successive attempts, starting at attempt. The limit is computed once and
+// then doubled in place, so filling a whole schedule costs one random draw per
+// element.
+func Fill(dst []time.Duration, base, cap time.Duration, attempt int) {
+	if base <= 0 || cap <= 0 || attempt < 0 {
+		checkStrict(base, cap, attempt)
+		clear(dst)
+		return
+	}
+	fill(dst, int64(base), int64(cap), attempt)
+}
+
+// FillNanos is like [Fill] but takes and fills integer nanoseconds, like
+// [DurationNanos].
+func FillNanos(dst []int64, base, cap int64, attempt int) {
+	if base <= 0 || cap <= 0 || attempt < 0 {
+		checkStrict(time.Duration(base), time.Duration(cap), attempt)
+		clear(dst)
+		return
+	}
+	fill(dst, base, cap, attempt)
+}
+
+// fill implements [Fill] and [FillNanos] for valid parameters.
+func fill[T ~int64](dst []T, base, cap int64, attempt int) {
+	limit := limitNanos(base, cap, attempt)
+	for i := range dst {
+		if limit > 1 {
+			dst[i] = T(rand.N(limit))
+		} else {
+			dst[i] = 0
+		}
+
+		if limit > cap>>1 {
+			limit = cap
+		} else {
+			limit <<= 1
+		}
+	}
+}
+
+// limitNanos returns min(cap, base*2^attempt) without overflowing. Both base
+// and cap must be positive, and attempt must not be negative.
+func limitNanos(base, cap int64, attempt int) int64 {
+	if attempt >= 63 || base > cap>>attempt {
+		return cap
+	}
+	return base << attempt
+}
+
+// factorLimitNanos returns min(cap, base*factor^attempt) without overflowing.
+// Both base and cap must be positive, factor must be at least 1 and attempt
+// must not be negative.
+func factorLimitNanos(base, cap int64, factor float64, attempt int) int64 {
+	if factor == 2 {
+		return limitNanos(base, cap, attempt)
+	}
+	if base >= cap {
+		return cap
+	}
+	if l := float64(base) * math.Pow(factor, float64(attempt)); l < float64(cap) {
+		return int64(l)
+	}
+	return cap
+}
+
+// Sleep blocks for the delay produced by [Duration]. It returns immediately
+// without touching the runtime timer when the delay is zero.
 func Sleep(base, cap time.Duration, attempt int) {
-	time.Sleep(Duration(base, cap, attempt))
+	if delay := Duration(base, cap, attempt); delay > 0 {
+		time.Sleep(delay)
+	}
+}
+
+// SleepContext is like [Sleep] but returns ctx.Err() as soon as ctx is done,
+// so that shutdown paths do not hang for the full delay. It returns ctx.Err()
+// without waiting when the delay is zero.
+func SleepContext(ctx context.Context, base, cap time.Duration, attempt int) error {
+	if delay := Duration(base, cap, attempt); delay > 0 {
+		return sleep(ctx, delay)
+	}
+	return ctx.Err()
 }
 
 // After returns a channel that will deliver the current time after the delay
-// produced by [Duration]. It is shorthand for
-// time.After(Duration(base, cap, attempt)).
+// produced by [Duration]. When the delay is zero, the returned channel already
+// holds the current time and no timer is created.
 func After(base, cap time.Duration, attempt int) <-chan time.Time {
-	return time.After(Duration(base, cap, attempt))
+	delay := Duration(base, cap, attempt)
+	if delay <= 0 {
+		c := make(chan time.Time, 1)
+		c <- time.Now()
+		return c
+	}
+	return time.After(delay)
+}
+
+// SleepOrWake is like [Sleep] but also returns early when ctx is done, in
+// which case it returns ctx.Err(), or when wake receives a value or is closed,
+// in which case it reports woken. It suits loops where an external event, such
+// as a configuration change or a recovery notification, should cut the current
+// backoff short. A nil wake never wakes.
+func SleepOrWake(ctx context.Context, wake <-chan struct{}, base, cap time.Duration, attempt int) (woken bool, err error) {
+	delay := Duration(base, cap, attempt)
+	if delay <= 0 {
+		return false, ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	case <-wake:
+		return true, nil
+	case <-timer.C:
+		return false, nil
+	}
 }
 
 // Attempts returns an iterator that yields zero-based attempts and waits for
 // the delay from [Duration] between successive attempts.
 func Attempts(ctx context.Context, maxAttempts int, base, cap time.Duration) iter.Seq[int] {
-	return func(yield func(int) bool) {
+	if maxAttempts <= 0 {
+		return func(func(int) bool) {}
+	}
+	p := &Policy{Base: base, Cap: cap, MaxAttempts: maxAttempts}
+	return p.Attempts(ctx)
+}
+
+// AttemptsForever is like [Attempts] but yields attempts until ctx is done or
+// the consumer breaks, which suits long-lived reconnect loops.
+func AttemptsForever(ctx context.Context, base, cap time.Duration) iter.Seq[int] {
+	p := &Policy{Base: base, Cap: cap}
+	return p.Attempts(ctx)
+}
+
+// AttemptsWithin is like [Attempts] but also stops once the next attempt would
+// start more than maxElapsedTime after the first one started, which bounds
+// the whole retry sequence by wall-clock time rather than by attempt count
+// alone. Unlike [Attempts], it does not limit the number of attempts if
+// maxAttempts is not positive.
+func AttemptsWithin(ctx context.Context, maxAttempts int, maxElapsedTime, base, cap time.Duration) iter.Seq[int] {
+	p := &Policy{Base: base, Cap: cap, MaxAttempts: maxAttempts, MaxElapsedTime: maxElapsedTime}
+	return p.Attempts(ctx)
+}
+
+// Attempts2 is like [Attempts] but also yields the delay waited before each
+// attempt, which is zero for the first one, so that the actual waits can be
+// logged or recorded without re-deriving them.
+func Attempts2(ctx context.Context, maxAttempts int, base, cap time.Duration) iter.Seq2[int, time.Duration] {
+	return func(yield func(int, time.Duration) bool) {
 		if maxAttempts <= 0 {
 			return
 		}
 
-		var timer *time.Timer
-		for attempt := range maxAttempts {
-			if ctx.Err() != nil {
+		var delay time.Duration
+		p := &Policy{
+			Base:        base,
+			Cap:         cap,
+			MaxAttempts: maxAttempts,
+			Record:      func(_ int, d time.Duration) { delay = d },
+		}
+		for attempt := range p.Attempts(ctx) {
+			if !yield(attempt, delay) {
 				return
 			}
+		}
+	}
+}
 
-			if !yield(attempt) {
-				return
-			}
+// AttemptsE is like [Attempts] but returns an error wrapping one of
+// [ErrInvalidMaxAttempts], [ErrInvalidBase], [ErrInvalidCap] or
+// [ErrBaseExceedsCap] for invalid parameters instead of silently yielding
+// nothing, not waiting, or always waiting up to cap.
+func AttemptsE(ctx context.Context, maxAttempts int, base, cap time.Duration) (iter.Seq[int], error) {
+	if maxAttempts <= 0 {
+		return nil, fmt.Errorf("%w: got %d", ErrInvalidMaxAttempts, maxAttempts)
+	}
+	if err := validate(base, cap); err != nil {
+		return nil, err
+	}
+	return Attempts(ctx, maxAttempts, base, cap), nil
+}
 
-			if attempt+1 < maxAttempts {
-				delay := Duration(base, cap, attempt)
-				if delay <= 0 {
-					continue
-				}
-
-				if timer == nil {
-					timer = time.NewTimer(delay)
-					defer timer.Stop()
-				} else {
-					timer.Reset(delay)
-				}
-
-				select {
-				case <-ctx.Done():
-					return
-				case <-timer.C:
-				}
-			}
+// randMu serializes the draws from the [rand.Rand] sources set as
+// [Policy.Rand], which are not safe for concurrent use on their own.
+var randMu sync.Mutex
+
+// randN returns a random number drawn uniformly from [0, n) using r, or the
+// top-level random source if r is nil. It returns 0 if n <= 1.
+func randN(r *rand.Rand, n int64) int64 {
+	switch {
+	case n <= 1:
+		return 0
+	case r == nil:
+		return rand.N(n)
+	}
+	randMu.Lock()
+	defer randMu.Unlock()
+	return r.Int64N(n)
+}
+
+// jittered returns a delay drawn uniformly from
+// [d*(1-fraction), d*(1+fraction)), where fraction is clamped to [0, 1]. It is
+// used to desynchronize periodic work across a fleet.
+func jittered(d time.Duration, fraction float64) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	spread := saturatingDuration(float64(d) * min(max(fraction, 0), 1))
+	lo, hi := d-spread, d+min(spread, math.MaxInt64-d)
+	return lo + time.Duration(randN(nil, int64(hi-lo)))
+}
+
+// checkStrict panics with the error reported by [DurationE] for the parameters
+// if the package is built with the backoff_strict tag. It is called wherever
+// invalid parameters would otherwise be silently treated as "no delay".
+func checkStrict(base, cap time.Duration, attempt int) {
+	if strict {
+		if _, err := DurationE(base, cap, attempt); err != nil {
+			panic(err)
 		}
 	}
 }
+
+// validate reports whether base and cap are valid.
+func validate(base, cap time.Duration) error {
+	if base <= 0 {
+		return fmt.Errorf("%w: got %v", ErrInvalidBase, base)
+	}
+	if cap <= 0 {
+		return fmt.Errorf("%w: got %v", ErrInvalidCap, cap)
+	}
+	if base > cap {
+		return fmt.Errorf("%w: got base %v and cap %v", ErrBaseExceedsCap, base, cap)
+	}
+	return nil
+}
+
+// validateMultiplier reports whether m is a valid growth factor.
+func validateMultiplier(m float64) error {
+	if !(m >= 1) || math.IsInf(m, 1) {
+		return fmt.Errorf("%w: got %v", ErrInvalidMultiplier, m)
+	}
+	return nil
+}
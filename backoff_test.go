@@ -2,6 +2,8 @@ package backoff
 
 import (
 	"context"
+	"errors"
+	"math"
 	"slices"
 	"testing"
 	"time"
@@ -99,6 +101,705 @@ func TestDuration(t *testing.T) {
 	}
 }
 
+func TestDurationNanos(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		base    int64
+		cap     int64
+		attempt int
+		wantMax int64
+	}{
+		{
+			name:    "ZeroBase",
+			base:    0,
+			cap:     int64(time.Second),
+			attempt: 0,
+			wantMax: 0,
+		},
+		{
+			name:    "NegativeAttempt",
+			base:    int64(time.Millisecond),
+			cap:     int64(time.Second),
+			attempt: -1,
+			wantMax: 0,
+		},
+		{
+			name:    "SecondAttempt",
+			base:    int64(100 * time.Millisecond),
+			cap:     int64(10 * time.Second),
+			attempt: 1,
+			wantMax: int64(200 * time.Millisecond),
+		},
+		{
+			name:    "LargeAttemptNumber",
+			base:    int64(time.Millisecond),
+			cap:     int64(time.Second),
+			attempt: 100,
+			wantMax: int64(time.Second),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			for range 10 {
+				got := DurationNanos(tt.base, tt.cap, tt.attempt)
+				if tt.wantMax == 0 {
+					if got != 0 {
+						t.Errorf("got %d, want 0", got)
+					}
+				} else if got < 0 || got >= tt.wantMax {
+					t.Errorf("got %d, want range [0, %d)", got, tt.wantMax)
+				}
+			}
+		})
+	}
+}
+
+func TestDurationWithLimit(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		base      time.Duration
+		cap       time.Duration
+		attempt   int
+		wantLimit time.Duration
+	}{
+		{
+			name:      "ZeroBase",
+			base:      0,
+			cap:       time.Second,
+			attempt:   0,
+			wantLimit: 0,
+		},
+		{
+			name:      "SecondAttempt",
+			base:      100 * time.Millisecond,
+			cap:       10 * time.Second,
+			attempt:   1,
+			wantLimit: 200 * time.Millisecond,
+		},
+		{
+			name:      "CappedByMaximum",
+			base:      100 * time.Millisecond,
+			cap:       300 * time.Millisecond,
+			attempt:   3,
+			wantLimit: 300 * time.Millisecond,
+		},
+		{
+			name:      "LimitEqualsOne",
+			base:      time.Nanosecond,
+			cap:       time.Nanosecond,
+			attempt:   0,
+			wantLimit: time.Nanosecond,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			for range 10 {
+				got, gotLimit := DurationWithLimit(tt.base, tt.cap, tt.attempt)
+				if gotLimit != tt.wantLimit {
+					t.Errorf("got limit %v, want %v", gotLimit, tt.wantLimit)
+				}
+				if got < 0 || (got > 0 && got >= gotLimit) {
+					t.Errorf("got %v, want range [0, %v)", got, gotLimit)
+				}
+			}
+		})
+	}
+}
+
+func TestDurationCapped(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		base       time.Duration
+		cap        time.Duration
+		attempt    int
+		wantMax    time.Duration
+		wantCapped bool
+	}{
+		{
+			name:    "ZeroBase",
+			base:    0,
+			cap:     time.Second,
+			attempt: 0,
+		},
+		{
+			name:    "BelowCap",
+			base:    100 * time.Millisecond,
+			cap:     time.Second,
+			attempt: 1,
+			wantMax: 200 * time.Millisecond,
+		},
+		{
+			name:       "ReachesCap",
+			base:       250 * time.Millisecond,
+			cap:        time.Second,
+			attempt:    2,
+			wantMax:    time.Second,
+			wantCapped: true,
+		},
+		{
+			name:       "CappedByMaximum",
+			base:       100 * time.Millisecond,
+			cap:        time.Second,
+			attempt:    100,
+			wantMax:    time.Second,
+			wantCapped: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotCapped := DurationCapped(tt.base, tt.cap, tt.attempt)
+			if gotCapped != tt.wantCapped {
+				t.Errorf("got capped %t, want %t", gotCapped, tt.wantCapped)
+			}
+			if tt.wantMax == 0 {
+				if got != 0 {
+					t.Errorf("got %v, want 0", got)
+				}
+			} else if got < 0 || got >= tt.wantMax {
+				t.Errorf("got %v, want range [0, %v)", got, tt.wantMax)
+			}
+		})
+	}
+}
+
+func TestDurationE(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		base    time.Duration
+		cap     time.Duration
+		attempt int
+		wantErr error
+	}{
+		{
+			name:    "Valid",
+			base:    time.Millisecond,
+			cap:     time.Second,
+			attempt: 1,
+		},
+		{
+			name:    "ZeroBase",
+			base:    0,
+			cap:     time.Second,
+			wantErr: ErrInvalidBase,
+		},
+		{
+			name:    "NegativeCap",
+			base:    time.Millisecond,
+			cap:     -time.Second,
+			wantErr: ErrInvalidCap,
+		},
+		{
+			name:    "BaseExceedsCap",
+			base:    time.Second,
+			cap:     time.Millisecond,
+			wantErr: ErrBaseExceedsCap,
+		},
+		{
+			name:    "NegativeAttempt",
+			base:    time.Millisecond,
+			cap:     time.Second,
+			attempt: -1,
+			wantErr: ErrInvalidAttempt,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DurationE(tt.base, tt.cap, tt.attempt)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+			if wantMax := 2 * time.Millisecond; got < 0 || got >= wantMax {
+				t.Errorf("got %v, want range [0, %v)", got, wantMax)
+			}
+		})
+	}
+}
+
+func TestDurationElapsed(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		base    time.Duration
+		cap     time.Duration
+		elapsed time.Duration
+		wantMax time.Duration
+	}{
+		{
+			name:    "ZeroBase",
+			base:    0,
+			cap:     time.Second,
+			elapsed: time.Second,
+			wantMax: 0,
+		},
+		{
+			name:    "JustFailed",
+			base:    100 * time.Millisecond,
+			cap:     10 * time.Second,
+			elapsed: 0,
+			wantMax: 100 * time.Millisecond,
+		},
+		{
+			name:    "FailingForAWhile",
+			base:    100 * time.Millisecond,
+			cap:     10 * time.Second,
+			elapsed: 3 * time.Second,
+			wantMax: 3 * time.Second,
+		},
+		{
+			name:    "CappedByMaximum",
+			base:    100 * time.Millisecond,
+			cap:     10 * time.Second,
+			elapsed: time.Hour,
+			wantMax: 10 * time.Second,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			for range 10 {
+				got := DurationElapsed(tt.base, tt.cap, tt.elapsed)
+				if tt.wantMax == 0 {
+					if got != 0 {
+						t.Errorf("got %v, want 0", got)
+					}
+				} else if got < 0 || got >= tt.wantMax {
+					t.Errorf("got %v, want range [0, %v)", got, tt.wantMax)
+				}
+			}
+		})
+	}
+}
+
+func TestDurationAt(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		base      time.Duration
+		cap       time.Duration
+		attempt   float64
+		wantLimit time.Duration
+	}{
+		{
+			name:      "ZeroBase",
+			base:      0,
+			cap:       time.Second,
+			attempt:   0,
+			wantLimit: 0,
+		},
+		{
+			name:      "NegativeAttempt",
+			base:      time.Second,
+			cap:       time.Second,
+			attempt:   -0.5,
+			wantLimit: 0,
+		},
+		{
+			name:      "NaNAttempt",
+			base:      time.Second,
+			cap:       time.Second,
+			attempt:   math.NaN(),
+			wantLimit: 0,
+		},
+		{
+			name:      "IntegerAttempt",
+			base:      100 * time.Millisecond,
+			cap:       10 * time.Second,
+			attempt:   2,
+			wantLimit: 400 * time.Millisecond,
+		},
+		{
+			name:      "FractionalAttempt",
+			base:      100 * time.Millisecond,
+			cap:       10 * time.Second,
+			attempt:   1.5,
+			wantLimit: time.Duration(float64(100*time.Millisecond) * math.Sqrt(8)),
+		},
+		{
+			name:      "CappedByMaximum",
+			base:      100 * time.Millisecond,
+			cap:       300 * time.Millisecond,
+			attempt:   2.5,
+			wantLimit: 300 * time.Millisecond,
+		},
+		{
+			name:      "InfiniteAttempt",
+			base:      time.Second,
+			cap:       time.Minute,
+			attempt:   math.Inf(1),
+			wantLimit: time.Minute,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			for range 100 {
+				got := DurationAt(tt.base, tt.cap, tt.attempt)
+				if tt.wantLimit == 0 {
+					if got != 0 {
+						t.Fatalf("got %v, want 0", got)
+					}
+					continue
+				}
+				if got < 0 || got >= tt.wantLimit {
+					t.Fatalf("got %v, want range [0, %v)", got, tt.wantLimit)
+				}
+			}
+		})
+	}
+}
+
+func TestDurationRange(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		min     time.Duration
+		base    time.Duration
+		cap     time.Duration
+		attempt int
+		wantMin time.Duration
+		wantMax time.Duration
+	}{
+		{
+			name:    "ZeroBase",
+			min:     10 * time.Millisecond,
+			base:    0,
+			cap:     time.Second,
+			attempt: 0,
+			wantMin: 0,
+			wantMax: 0,
+		},
+		{
+			name:    "ZeroMin",
+			min:     0,
+			base:    100 * time.Millisecond,
+			cap:     time.Second,
+			attempt: 1,
+			wantMin: 0,
+			wantMax: 200*time.Millisecond - 1,
+		},
+		{
+			name:    "NegativeMin",
+			min:     -time.Second,
+			base:    100 * time.Millisecond,
+			cap:     time.Second,
+			attempt: 1,
+			wantMin: 0,
+			wantMax: 200*time.Millisecond - 1,
+		},
+		{
+			name:    "Floor",
+			min:     50 * time.Millisecond,
+			base:    100 * time.Millisecond,
+			cap:     time.Second,
+			attempt: 1,
+			wantMin: 50 * time.Millisecond,
+			wantMax: 200*time.Millisecond - 1,
+		},
+		{
+			name:    "MinAboveLimit",
+			min:     2 * time.Second,
+			base:    100 * time.Millisecond,
+			cap:     time.Second,
+			attempt: 10,
+			wantMin: 2 * time.Second,
+			wantMax: 2 * time.Second,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			for range 100 {
+				got := DurationRange(tt.min, tt.base, tt.cap, tt.attempt)
+				if got < tt.wantMin || got > tt.wantMax {
+					t.Fatalf("got %v, want range [%v, %v]", got, tt.wantMin, tt.wantMax)
+				}
+			}
+		})
+	}
+}
+
+func TestDurationFactor(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		base    time.Duration
+		cap     time.Duration
+		factor  float64
+		attempt int
+		wantMax time.Duration
+	}{
+		{
+			name:    "ZeroBase",
+			base:    0,
+			cap:     time.Second,
+			factor:  1.5,
+			attempt: 0,
+			wantMax: 0,
+		},
+		{
+			name:    "FactorBelowOne",
+			base:    100 * time.Millisecond,
+			cap:     time.Second,
+			factor:  0.5,
+			attempt: 1,
+			wantMax: 0,
+		},
+		{
+			name:    "InfiniteFactor",
+			base:    100 * time.Millisecond,
+			cap:     time.Second,
+			factor:  math.Inf(1),
+			attempt: 1,
+			wantMax: 0,
+		},
+		{
+			name:    "OneAndAHalf",
+			base:    100 * time.Millisecond,
+			cap:     time.Second,
+			factor:  1.5,
+			attempt: 2,
+			wantMax: 225 * time.Millisecond,
+		},
+		{
+			name:    "One",
+			base:    100 * time.Millisecond,
+			cap:     time.Second,
+			factor:  1,
+			attempt: 100,
+			wantMax: 100 * time.Millisecond,
+		},
+		{
+			name:    "LargeAttempt",
+			base:    100 * time.Millisecond,
+			cap:     time.Second,
+			factor:  1.5,
+			attempt: math.MaxInt,
+			wantMax: time.Second,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			for range 100 {
+				got := DurationFactor(tt.base, tt.cap, tt.factor, tt.attempt)
+				if got < 0 || (tt.wantMax > 0 && got >= tt.wantMax) || (tt.wantMax == 0 && got != 0) {
+					t.Fatalf("got %v, want range [0, %v)", got, tt.wantMax)
+				}
+			}
+		})
+	}
+}
+
+func TestMaxDuration(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		base    time.Duration
+		cap     time.Duration
+		attempt int
+		want    time.Duration
+	}{
+		{
+			name:    "ZeroBase",
+			base:    0,
+			cap:     time.Second,
+			attempt: 0,
+			want:    0,
+		},
+		{
+			name:    "NegativeAttempt",
+			base:    time.Second,
+			cap:     time.Second,
+			attempt: -1,
+			want:    0,
+		},
+		{
+			name:    "SecondAttempt",
+			base:    100 * time.Millisecond,
+			cap:     10 * time.Second,
+			attempt: 1,
+			want:    200*time.Millisecond - 1,
+		},
+		{
+			name:    "CappedByMaximum",
+			base:    100 * time.Millisecond,
+			cap:     300 * time.Millisecond,
+			attempt: 3,
+			want:    300*time.Millisecond - 1,
+		},
+		{
+			name:    "LargeAttempt",
+			base:    time.Second,
+			cap:     time.Minute,
+			attempt: 1000,
+			want:    time.Minute - 1,
+		},
+		{
+			name:    "LimitEqualsOne",
+			base:    time.Nanosecond,
+			cap:     time.Nanosecond,
+			attempt: 0,
+			want:    0,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MaxDuration(tt.base, tt.cap, tt.attempt); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTotalMaxWait(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		base        time.Duration
+		cap         time.Duration
+		maxAttempts int
+		want        time.Duration
+	}{
+		{
+			name:        "SingleAttempt",
+			base:        time.Second,
+			cap:         time.Minute,
+			maxAttempts: 1,
+			want:        0,
+		},
+		{
+			name:        "Uncapped",
+			base:        100 * time.Millisecond,
+			cap:         time.Minute,
+			maxAttempts: 4,
+			want:        700*time.Millisecond - 3,
+		},
+		{
+			name:        "Capped",
+			base:        100 * time.Millisecond,
+			cap:         200 * time.Millisecond,
+			maxAttempts: 4,
+			want:        500*time.Millisecond - 3,
+		},
+		{
+			name:        "Saturates",
+			base:        time.Duration(math.MaxInt64 / 2),
+			cap:         math.MaxInt64,
+			maxAttempts: 10,
+			want:        math.MaxInt64,
+		},
+		{
+			name:        "LongTail",
+			base:        time.Second,
+			cap:         4 * time.Second,
+			maxAttempts: 1_000_001,
+			want:        3*time.Second - 2 + 999_998*(4*time.Second-1),
+		},
+		{
+			name:        "MaxAttemptsSaturates",
+			base:        time.Second,
+			cap:         time.Minute,
+			maxAttempts: math.MaxInt,
+			want:        math.MaxInt64,
+		},
+		{
+			name:        "MaxAttemptsZeroDelays",
+			base:        time.Nanosecond,
+			cap:         time.Nanosecond,
+			maxAttempts: math.MaxInt,
+			want:        0,
+		},
+		{
+			name:        "Invalid",
+			base:        0,
+			cap:         time.Second,
+			maxAttempts: 10,
+			want:        0,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TotalMaxWait(tt.base, tt.cap, tt.maxAttempts); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTotalMaxWaitWithTimeout(t *testing.T) {
+	for _, tt := range []struct {
+		name           string
+		maxAttempts    int
+		attemptTimeout time.Duration
+		want           time.Duration
+	}{
+		{
+			name:           "IncludesTimeouts",
+			maxAttempts:    4,
+			attemptTimeout: time.Second,
+			want:           4*time.Second + 700*time.Millisecond - 3,
+		},
+		{
+			name:           "NoTimeout",
+			maxAttempts:    4,
+			attemptTimeout: 0,
+			want:           700*time.Millisecond - 3,
+		},
+		{
+			name:           "Saturates",
+			maxAttempts:    4,
+			attemptTimeout: math.MaxInt64 / 2,
+			want:           math.MaxInt64,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TotalMaxWaitWithTimeout(100*time.Millisecond, time.Minute, tt.maxAttempts, tt.attemptTimeout); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFill(t *testing.T) {
+	t.Run("Envelopes", func(t *testing.T) {
+		base := 100 * time.Millisecond
+		cap := time.Second
+		dst := make([]time.Duration, 8)
+		for range 10 {
+			Fill(dst, base, cap, 1)
+			for i, got := range dst {
+				wantMax := min(cap, base<<(i+1))
+				if got < 0 || got >= wantMax {
+					t.Errorf("got %v at %d, want range [0, %v)", got, i, wantMax)
+				}
+			}
+		}
+	})
+
+	t.Run("OddCap", func(t *testing.T) {
+		dst := make([]time.Duration, 4)
+		Fill(dst, time.Nanosecond, 3*time.Nanosecond, 0)
+		for i, got := range dst {
+			if got < 0 || got >= 3 {
+				t.Errorf("got %v at %d, want range [0, 3ns)", got, i)
+			}
+		}
+	})
+
+	t.Run("InvalidParameters", func(t *testing.T) {
+		dst := []time.Duration{1, 2, 3}
+		Fill(dst, 0, time.Second, 0)
+		if want := []time.Duration{0, 0, 0}; !slices.Equal(dst, want) {
+			t.Errorf("got %v, want %v", dst, want)
+		}
+	})
+}
+
+func TestFillNanos(t *testing.T) {
+	t.Run("Envelopes", func(t *testing.T) {
+		base := int64(100 * time.Millisecond)
+		cap := int64(time.Second)
+		dst := make([]int64, 8)
+		for range 10 {
+			FillNanos(dst, base, cap, 1)
+			for i, got := range dst {
+				wantMax := min(cap, base<<(i+1))
+				if got < 0 || got >= wantMax {
+					t.Errorf("got %d at %d, want range [0, %d)", got, i, wantMax)
+				}
+			}
+		}
+	})
+
+	t.Run("InvalidParameters", func(t *testing.T) {
+		dst := []int64{1, 2, 3}
+		FillNanos(dst, 0, int64(time.Second), 0)
+		if want := []int64{0, 0, 0}; !slices.Equal(dst, want) {
+			t.Errorf("got %v, want %v", dst, want)
+		}
+	})
+}
+
 func TestSleep(t *testing.T) {
 	base := 5 * time.Millisecond
 	cap := 20 * time.Millisecond
@@ -119,6 +820,34 @@ func TestSleep(t *testing.T) {
 	}
 }
 
+func TestSleepContext(t *testing.T) {
+	t.Run("Elapses", func(t *testing.T) {
+		if err := SleepContext(context.Background(), time.Millisecond, time.Millisecond, 0); err != nil {
+			t.Errorf("got %v, want nil", err)
+		}
+	})
+
+	t.Run("ContextCanceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+		start := time.Now()
+		if err := SleepContext(ctx, time.Hour, time.Hour, 0); !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want %v", err, context.Canceled)
+		}
+		if elapsed := time.Since(start); elapsed > time.Minute {
+			t.Errorf("got %v, want < %v", elapsed, time.Minute)
+		}
+	})
+
+	t.Run("ZeroDelay", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := SleepContext(ctx, 0, time.Second, 0); !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want %v", err, context.Canceled)
+		}
+	})
+}
+
 func TestAfter(t *testing.T) {
 	base := 10 * time.Millisecond
 	cap := 50 * time.Millisecond
@@ -149,6 +878,61 @@ func TestAfter(t *testing.T) {
 	case <-time.After(wantMax + 100*time.Millisecond):
 		t.Error("got timeout, want timely delivery")
 	}
+
+	t.Run("ZeroDelay", func(t *testing.T) {
+		select {
+		case <-After(0, time.Second, 0):
+		default:
+			t.Error("got empty channel, want ready channel")
+		}
+	})
+}
+
+func TestSleepOrWake(t *testing.T) {
+	t.Run("Elapses", func(t *testing.T) {
+		woken, err := SleepOrWake(context.Background(), nil, time.Millisecond, time.Millisecond, 0)
+		if err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		if woken {
+			t.Error("got woken, want elapsed")
+		}
+	})
+
+	t.Run("Wakes", func(t *testing.T) {
+		wake := make(chan struct{})
+		time.AfterFunc(10*time.Millisecond, func() { close(wake) })
+		start := time.Now()
+		woken, err := SleepOrWake(context.Background(), wake, time.Hour, time.Hour, 0)
+		if err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		if !woken {
+			t.Error("got elapsed, want woken")
+		}
+		if elapsed := time.Since(start); elapsed > time.Minute {
+			t.Errorf("got %v, want < %v", elapsed, time.Minute)
+		}
+	})
+
+	t.Run("ContextCanceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		woken, err := SleepOrWake(ctx, make(chan struct{}), time.Hour, time.Hour, 0)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want %v", err, context.Canceled)
+		}
+		if woken {
+			t.Error("got woken, want canceled")
+		}
+	})
+
+	t.Run("ZeroDelay", func(t *testing.T) {
+		woken, err := SleepOrWake(context.Background(), nil, 0, time.Second, 0)
+		if err != nil || woken {
+			t.Errorf("got (%t, %v), want (false, nil)", woken, err)
+		}
+	})
 }
 
 func TestAttempts(t *testing.T) {
@@ -195,6 +979,20 @@ func TestAttempts(t *testing.T) {
 		}
 	})
 
+	t.Run("ReturnsPromptlyWhenContextCanceledMidWait", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		maxAttempts := 2
+
+		startTime := time.Now()
+		for range Attempts(ctx, maxAttempts, time.Hour, time.Hour) {
+			time.AfterFunc(time.Millisecond, cancel)
+		}
+		if elapsed, wantMax := time.Since(startTime), time.Second; elapsed > wantMax {
+			t.Errorf("got %v, want <= %v", elapsed, wantMax)
+		}
+	})
+
 	t.Run("NoAttemptsWhenContextCanceled", func(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		cancel()
@@ -216,3 +1014,173 @@ func TestAttempts(t *testing.T) {
 		}
 	})
 }
+
+func TestAttemptsForever(t *testing.T) {
+	t.Run("StopsWhenContextCanceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+
+		var got []int
+		for attempt := range AttemptsForever(ctx, time.Nanosecond, time.Nanosecond) {
+			got = append(got, attempt)
+			if attempt == 99 {
+				cancel()
+			}
+		}
+		if len(got) != 100 || got[99] != 99 {
+			t.Errorf("got %d attempts, want 100", len(got))
+		}
+	})
+
+	t.Run("StopsWhenConsumerBreaks", func(t *testing.T) {
+		var got []int
+		for attempt := range AttemptsForever(context.Background(), time.Nanosecond, time.Nanosecond) {
+			got = append(got, attempt)
+			if attempt == 1 {
+				break
+			}
+		}
+		if want := []int{0, 1}; !slices.Equal(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+}
+
+func TestAttemptsWithin(t *testing.T) {
+	t.Run("StopsAtMaxElapsedTime", func(t *testing.T) {
+		maxElapsedTime := 100 * time.Millisecond
+		startTime := time.Now()
+		got := slices.Collect(AttemptsWithin(context.Background(), 0, maxElapsedTime, 20*time.Millisecond, time.Second))
+		if len(got) == 0 {
+			t.Error("got no attempts, want at least 1")
+		}
+		tolerance := 20 * time.Millisecond
+		if elapsed := time.Since(startTime); elapsed > maxElapsedTime+tolerance {
+			t.Errorf("got %v elapsed, want <= %v", elapsed, maxElapsedTime+tolerance)
+		}
+	})
+
+	t.Run("StopsAtMaxAttempts", func(t *testing.T) {
+		got := slices.Collect(AttemptsWithin(context.Background(), 3, time.Hour, time.Nanosecond, time.Nanosecond))
+		if want := []int{0, 1, 2}; !slices.Equal(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+}
+
+func TestAttempts2(t *testing.T) {
+	t.Run("YieldsDelays", func(t *testing.T) {
+		base, cap := time.Millisecond, 4*time.Millisecond
+
+		var attempts []int
+		var total time.Duration
+		startTime := time.Now()
+		for attempt, delay := range Attempts2(context.Background(), 4, base, cap) {
+			attempts = append(attempts, attempt)
+			wantMax := time.Duration(0)
+			if attempt > 0 {
+				wantMax = min(cap, base<<(attempt-1)) - 1
+			}
+			if delay < 0 || delay > wantMax {
+				t.Errorf("got %v for attempt %d, want range [0, %v]", delay, attempt, wantMax)
+			}
+			total += delay
+		}
+		if want := []int{0, 1, 2, 3}; !slices.Equal(attempts, want) {
+			t.Errorf("got %v, want %v", attempts, want)
+		}
+		if elapsed := time.Since(startTime); elapsed < total {
+			t.Errorf("got %v elapsed, want at least %v", elapsed, total)
+		}
+	})
+
+	t.Run("StopsWhenConsumerBreaks", func(t *testing.T) {
+		var got []int
+		for attempt := range Attempts2(context.Background(), 3, time.Nanosecond, time.Nanosecond) {
+			got = append(got, attempt)
+			if attempt == 1 {
+				break
+			}
+		}
+		if want := []int{0, 1}; !slices.Equal(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("ZeroMaxAttempts", func(t *testing.T) {
+		for attempt := range Attempts2(context.Background(), 0, time.Nanosecond, time.Nanosecond) {
+			t.Errorf("got attempt %d, want none", attempt)
+		}
+	})
+}
+
+func TestAttemptsE(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		seq, err := AttemptsE(context.Background(), 2, time.Nanosecond, time.Nanosecond)
+		if err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		if got, want := slices.Collect(seq), []int{0, 1}; !slices.Equal(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("ZeroMaxAttempts", func(t *testing.T) {
+		if _, err := AttemptsE(context.Background(), 0, time.Nanosecond, time.Nanosecond); !errors.Is(err, ErrInvalidMaxAttempts) {
+			t.Errorf("got %v, want %v", err, ErrInvalidMaxAttempts)
+		}
+	})
+
+	t.Run("ZeroBase", func(t *testing.T) {
+		if _, err := AttemptsE(context.Background(), 1, 0, time.Nanosecond); !errors.Is(err, ErrInvalidBase) {
+			t.Errorf("got %v, want %v", err, ErrInvalidBase)
+		}
+	})
+}
+
+func TestJittered(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		d        time.Duration
+		fraction float64
+		wantMin  time.Duration
+		wantMax  time.Duration
+	}{
+		{
+			name:     "NoJitter",
+			d:        time.Second,
+			fraction: 0,
+			wantMin:  time.Second,
+			wantMax:  time.Second + 1,
+		},
+		{
+			name:     "HalfJitter",
+			d:        time.Second,
+			fraction: 0.5,
+			wantMin:  500 * time.Millisecond,
+			wantMax:  1500 * time.Millisecond,
+		},
+		{
+			name:     "ClampedFraction",
+			d:        time.Second,
+			fraction: 2,
+			wantMin:  0,
+			wantMax:  2 * time.Second,
+		},
+		{
+			name:     "ZeroDuration",
+			d:        0,
+			fraction: 0.5,
+			wantMin:  0,
+			wantMax:  1,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			for range 10 {
+				if got := jittered(tt.d, tt.fraction); got < tt.wantMin || got >= tt.wantMax {
+					t.Errorf("got %v, want range [%v, %v)", got, tt.wantMin, tt.wantMax)
+				}
+			}
+		})
+	}
+}
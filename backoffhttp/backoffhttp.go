@@ -0,0 +1,124 @@
+/*
+Package backoffhttp provides helpers for retrying HTTP requests with package
+backoff.
+*/
+package backoffhttp
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aofei/backoff"
+)
+
+// Throttled reports whether resp is a throttling response of a cloud blob
+// store and returns the delay the server asked for, if any. The delay is
+// suitable as the hint of [backoff.Policy.DurationHint].
+//
+// A response with status 429 is a throttling response. A response with status
+// 503 is one only if it is an S3 SlowDown error, an Azure Storage ServerBusy
+// error, as told by its x-ms-error-code header, or a GCS error, as told by its
+// X-GUploader-UploadID header. To find the error code of S3, up to the first
+// 4 KiB of the body are read and then put back in front of the rest of it. A
+// nil resp is not a throttling response.
+func Throttled(resp *http.Response) (retryAfter time.Duration, ok bool) {
+	if resp == nil {
+		return 0, false
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+	case http.StatusServiceUnavailable:
+		if resp.Header.Get("X-Ms-Error-Code") != "ServerBusy" &&
+			resp.Header.Get("X-Guploader-Uploadid") == "" &&
+			!s3SlowDown(resp) {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+	retryAfter, _ = RetryAfter(resp.Header, time.Now())
+	return retryAfter, true
+}
+
+// RetryThrottled is a ShouldRetry of a [Transport] for data pipelines talking
+// to cloud blob stores. It retries the responses [Throttled] reports as
+// throttling, and errors that [backoff.ClassifyError] classifies as anything
+// but [backoff.ClassOther], such as the transient network errors that come
+// with a nil resp.
+func RetryThrottled(resp *http.Response, err error) bool {
+	if err != nil {
+		return backoff.ClassifyError(err) != backoff.ClassOther
+	}
+	_, ok := Throttled(resp)
+	return ok
+}
+
+// s3SlowDown reports whether the body of resp holds the SlowDown error code of
+// S3, leaving the body intact for the caller.
+func s3SlowDown(resp *http.Response) bool {
+	if resp.Body == nil || resp.Body == http.NoBody {
+		return false
+	}
+	prefix, err := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(prefix), resp.Body), resp.Body}
+	return err == nil && bytes.Contains(prefix, []byte("<Code>SlowDown</Code>"))
+}
+
+// RetryAfter returns the delay requested by the headers of a response,
+// relative to now. It honors the Retry-After header, in either delay-seconds or
+// HTTP-date form, and the retry-after-ms and x-ms-retry-after-ms headers used by
+// Azure, which take precedence because they are more precise. It reports false
+// if none of them holds a valid value. Delays too long for a [time.Duration]
+// saturate at math.MaxInt64.
+func RetryAfter(h http.Header, now time.Time) (time.Duration, bool) {
+	for _, key := range []string{"X-Ms-Retry-After-Ms", "Retry-After-Ms"} {
+		if v := h.Get(key); v != "" {
+			if ms, err := strconv.ParseInt(v, 10, 64); err == nil && ms >= 0 {
+				if ms > math.MaxInt64/int64(time.Millisecond) {
+					return math.MaxInt64, true
+				}
+				return time.Duration(ms) * time.Millisecond, true
+			}
+		}
+	}
+
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseInt(v, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		if seconds > math.MaxInt64/int64(time.Second) {
+			return math.MaxInt64, true
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// SetIdempotencyKey sets the Idempotency-Key header of req to the key carried
+// by its context, if any and the header is not set yet. See
+// [backoff.WithIdempotencyKey].
+func SetIdempotencyKey(req *http.Request) {
+	if req.Header.Get("Idempotency-Key") != "" {
+		return
+	}
+	if key, ok := backoff.IdempotencyKey(req.Context()); ok {
+		if req.Header == nil {
+			req.Header = http.Header{}
+		}
+		req.Header.Set("Idempotency-Key", key)
+	}
+}
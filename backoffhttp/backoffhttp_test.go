@@ -0,0 +1,237 @@
+package backoffhttp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aofei/backoff"
+)
+
+func TestThrottled(t *testing.T) {
+	for _, tt := range []struct {
+		name           string
+		status         int
+		header         http.Header
+		body           string
+		wantOK         bool
+		wantRetryAfter time.Duration
+	}{
+		{
+			name:   "OK",
+			status: http.StatusOK,
+		},
+		{
+			name:   "InternalServerError",
+			status: http.StatusInternalServerError,
+		},
+		{
+			name:   "S3SlowDown",
+			status: http.StatusServiceUnavailable,
+			body:   "<Error><Code>SlowDown</Code><Message>Please reduce your request rate.</Message></Error>",
+			wantOK: true,
+		},
+		{
+			name:   "S3ServiceUnavailable",
+			status: http.StatusServiceUnavailable,
+			body:   "<Error><Code>ServiceUnavailable</Code></Error>",
+		},
+		{
+			name:           "GCSTooManyRequests",
+			status:         http.StatusTooManyRequests,
+			header:         http.Header{"Retry-After": {"3"}},
+			wantOK:         true,
+			wantRetryAfter: 3 * time.Second,
+		},
+		{
+			name:   "GCSServiceUnavailable",
+			status: http.StatusServiceUnavailable,
+			header: http.Header{"X-Guploader-Uploadid": {"id"}},
+			wantOK: true,
+		},
+		{
+			name:           "AzureServerBusy",
+			status:         http.StatusServiceUnavailable,
+			header:         http.Header{"Retry-After": {"1"}, "X-Ms-Retry-After-Ms": {"1500"}, "X-Ms-Error-Code": {"ServerBusy"}},
+			wantOK:         true,
+			wantRetryAfter: 1500 * time.Millisecond,
+		},
+		{
+			name:   "UnknownServiceUnavailable",
+			status: http.StatusServiceUnavailable,
+			header: http.Header{"Retry-After": {"1"}},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: tt.header, Body: io.NopCloser(strings.NewReader(tt.body))}
+			if resp.Header == nil {
+				resp.Header = http.Header{}
+			}
+			retryAfter, ok := Throttled(resp)
+			if ok != tt.wantOK {
+				t.Errorf("got %t, want %t", ok, tt.wantOK)
+			}
+			if retryAfter != tt.wantRetryAfter {
+				t.Errorf("got %v, want %v", retryAfter, tt.wantRetryAfter)
+			}
+			if body, _ := io.ReadAll(resp.Body); string(body) != tt.body {
+				t.Errorf("got %q, want %q", body, tt.body)
+			}
+		})
+	}
+
+	t.Run("Nil", func(t *testing.T) {
+		if _, ok := Throttled(nil); ok {
+			t.Error("got true, want false")
+		}
+	})
+}
+
+func TestRetryThrottled(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		resp *http.Response
+		err  error
+		want bool
+	}{
+		{
+			name: "TooManyRequests",
+			resp: &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}},
+			want: true,
+		},
+		{
+			name: "InternalServerError",
+			resp: &http.Response{StatusCode: http.StatusInternalServerError, Header: http.Header{}},
+		},
+		{
+			name: "TransientError",
+			err:  context.DeadlineExceeded,
+			want: true,
+		},
+		{
+			name: "OtherError",
+			err:  errors.New("other"),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RetryThrottled(tt.resp, tt.err); got != tt.want {
+				t.Errorf("got %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+		wantOK bool
+	}{
+		{
+			name: "Missing",
+		},
+		{
+			name:   "Seconds",
+			header: http.Header{"Retry-After": {"120"}},
+			want:   2 * time.Minute,
+			wantOK: true,
+		},
+		{
+			name:   "HTTPDate",
+			header: http.Header{"Retry-After": {now.Add(30 * time.Second).Format(http.TimeFormat)}},
+			want:   30 * time.Second,
+			wantOK: true,
+		},
+		{
+			name:   "PastHTTPDate",
+			header: http.Header{"Retry-After": {now.Add(-time.Minute).Format(http.TimeFormat)}},
+			want:   0,
+			wantOK: true,
+		},
+		{
+			name:   "NegativeSeconds",
+			header: http.Header{"Retry-After": {"-1"}},
+		},
+		{
+			name:   "Garbage",
+			header: http.Header{"Retry-After": {"soon"}},
+		},
+		{
+			name:   "Milliseconds",
+			header: http.Header{"Retry-After-Ms": {"250"}},
+			want:   250 * time.Millisecond,
+			wantOK: true,
+		},
+		{
+			name:   "OverflowingSeconds",
+			header: http.Header{"Retry-After": {"18446744074"}},
+			want:   math.MaxInt64,
+			wantOK: true,
+		},
+		{
+			name:   "LargeSeconds",
+			header: http.Header{"Retry-After": {"10000000000"}},
+			want:   math.MaxInt64,
+			wantOK: true,
+		},
+		{
+			name:   "OverflowingMilliseconds",
+			header: http.Header{"X-Ms-Retry-After-Ms": {"9223372036855"}},
+			want:   math.MaxInt64,
+			wantOK: true,
+		},
+		{
+			name:   "InvalidMillisecondsFallsBack",
+			header: http.Header{"Retry-After-Ms": {"x"}, "Retry-After": {"2"}},
+			want:   2 * time.Second,
+			wantOK: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := RetryAfter(tt.header, now)
+			if ok != tt.wantOK {
+				t.Errorf("got %t, want %t", ok, tt.wantOK)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetIdempotencyKey(t *testing.T) {
+	t.Run("FromContext", func(t *testing.T) {
+		ctx := backoff.WithIdempotencyKey(context.Background(), "key")
+		req := httptest.NewRequestWithContext(ctx, http.MethodPost, "/", nil)
+		SetIdempotencyKey(req)
+		if got := req.Header.Get("Idempotency-Key"); got != "key" {
+			t.Errorf("got %q, want %q", got, "key")
+		}
+	})
+
+	t.Run("KeepsExisting", func(t *testing.T) {
+		ctx := backoff.WithIdempotencyKey(context.Background(), "key")
+		req := httptest.NewRequestWithContext(ctx, http.MethodPost, "/", nil)
+		req.Header.Set("Idempotency-Key", "existing")
+		SetIdempotencyKey(req)
+		if got := req.Header.Get("Idempotency-Key"); got != "existing" {
+			t.Errorf("got %q, want %q", got, "existing")
+		}
+	})
+
+	t.Run("NoKey", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		SetIdempotencyKey(req)
+		if got := req.Header.Get("Idempotency-Key"); got != "" {
+			t.Errorf("got %q, want empty", got)
+		}
+	})
+}
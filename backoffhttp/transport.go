@@ -0,0 +1,194 @@
+package backoffhttp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/aofei/backoff"
+)
+
+// Transport is an [http.RoundTripper] that retries idempotent requests on
+// throttling and server errors and on transient network errors, spacing the
+// attempts as [backoff.Policy.Retry] does under Policy.
+//
+// A request is idempotent if its method is GET, HEAD, OPTIONS, TRACE, PUT or
+// DELETE, or if it has an Idempotency-Key or X-Idempotency-Key header. The
+// Idempotency-Key header is set from the context of the request first, see
+// [SetIdempotencyKey]. If Policy sets IdempotencyKey and the request has no
+// key yet, a new one is generated, so every request becomes retryable with
+// the same key on all of its attempts. A request with a body is only retried if its GetBody
+// field is set, so that the body can be rewound.
+//
+// The delay after an attempt honors the delay requested by the response, if
+// any, according to Policy.Hint. See [RetryAfter] and
+// [backoff.WithDelayHint]. Unless Policy sets ClampToDeadline, the request is
+// not retried when waiting would outlive the deadline of the request context,
+// as if Policy set StopAtDeadline. Retried responses that [Throttled] reports,
+// such as those with status 429, count as throttled attempts for
+// Policy.Throttle, so the Transport sheds requests on the client side while
+// an upstream throttles. See [backoff.AdaptiveThrottle]. Once Policy gives up,
+// the response and error of the last attempt are returned, or ctx.Err() if
+// the request context is done while waiting.
+type Transport struct {
+	// Base is the underlying [http.RoundTripper]. If nil,
+	// [http.DefaultTransport] is used.
+	Base http.RoundTripper
+
+	// Policy spaces the attempts. It must not be nil.
+	Policy *backoff.Policy
+
+	// ShouldRetry reports whether an attempt that returned resp and err
+	// should be retried. If nil, responses with status 429 or 5xx other
+	// than 501 and 505 are retried, and errors are retried if
+	// [backoff.ClassifyError] classifies them as anything but
+	// [backoff.ClassOther].
+	ShouldRetry func(resp *http.Response, err error) bool
+
+	// OnAttempt, if not nil, is called after every attempt with its
+	// zero-based number, response and error.
+	OnAttempt func(req *http.Request, attempt int, resp *http.Response, err error)
+
+	// OnRetry, if not nil, is called before waiting for delay to retry
+	// after the attempt.
+	OnRetry func(req *http.Request, attempt int, delay time.Duration)
+}
+
+// errRetry is returned to [backoff.Policy.Retry] for an attempt of a
+// [Transport] that should be retried with a response rather than an error.
+var errRetry = errors.New("backoffhttp: retryable response")
+
+// errThrottled is like errRetry but for a throttling response, which
+// [backoff.ClassifyError] classifies as [backoff.ClassThrottled], so that it
+// feeds Policy.Throttle and the [backoff.Router] policy for throttling.
+var errThrottled error = throttledError{}
+
+// throttledError is the type of errThrottled.
+type throttledError struct{}
+
+// Error implements [error].
+func (throttledError) Error() string { return "backoffhttp: throttling response" }
+
+// Throttled reports that the response was a throttling one.
+func (throttledError) Throttled() bool { return true }
+
+// RoundTrip implements [http.RoundTripper].
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	shouldRetry := t.ShouldRetry
+	if shouldRetry == nil {
+		shouldRetry = retryable
+	}
+
+	ctx := req.Context()
+	if _, ok := backoff.IdempotencyKey(ctx); !ok && t.Policy.IdempotencyKey &&
+		req.Header.Get("Idempotency-Key") == "" {
+		ctx = backoff.WithIdempotencyKey(ctx, "")
+	}
+	r := req.Clone(ctx)
+	SetIdempotencyKey(r)
+	rewindable := r.Body == nil || r.Body == http.NoBody || r.GetBody != nil
+	canRetry := rewindable && idempotent(r)
+
+	var (
+		resp    *http.Response
+		err     error
+		drained bool
+	)
+	p := *t.Policy
+	p.StopAtDeadline = p.StopAtDeadline || !p.ClampToDeadline
+	p.Observe = func(e backoff.RetryEvent) {
+		if t.Policy.Observe != nil {
+			t.Policy.Observe(e)
+		}
+		if e.Outcome != backoff.OutcomeRetry {
+			return
+		}
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+			resp.Body.Close()
+			drained = true
+		}
+		if t.OnRetry != nil {
+			t.OnRetry(r, e.Attempt, e.Delay)
+		}
+	}
+	attempt := 0
+	retryErr := p.Retry(ctx, func(ctx context.Context) error {
+		if attempt > 0 && r.GetBody != nil {
+			body, bodyErr := r.GetBody()
+			if bodyErr != nil {
+				resp, err, drained = nil, bodyErr, false
+				return backoff.Permanent(err)
+			}
+			r = r.Clone(ctx)
+			r.Body = body
+		}
+
+		resp, err = base.RoundTrip(r)
+		drained = false
+		if t.OnAttempt != nil {
+			t.OnAttempt(r, attempt, resp, err)
+		}
+		attempt++
+		if !canRetry || !shouldRetry(resp, err) {
+			return nil
+		}
+
+		var hint time.Duration
+		if resp != nil {
+			hint, _ = RetryAfter(resp.Header, time.Now())
+		}
+		if err != nil {
+			return backoff.WithDelayHint(err, hint)
+		}
+		if _, ok := Throttled(resp); ok {
+			return backoff.WithDelayHint(errThrottled, hint)
+		}
+		return backoff.WithDelayHint(errRetry, hint)
+	})
+	if attempt == 0 {
+		return nil, retryErr
+	}
+	if drained {
+		// Policy gave up after the response was discarded, while waiting
+		// or reauthenticating.
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, retryErr
+	}
+	return resp, err
+}
+
+// idempotent reports whether req may be sent more than once.
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	_, ok := req.Header["Idempotency-Key"]
+	if !ok {
+		_, ok = req.Header["X-Idempotency-Key"]
+	}
+	return ok
+}
+
+// retryable is the default ShouldRetry of a [Transport].
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return backoff.ClassifyError(err) != backoff.ClassOther
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusNotImplemented, http.StatusHTTPVersionNotSupported:
+		return false
+	}
+	return resp.StatusCode >= 500
+}
@@ -0,0 +1,315 @@
+package backoffhttp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/aofei/backoff"
+)
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestTransport(t *testing.T) {
+	policy := &backoff.Policy{Base: time.Millisecond, Cap: time.Millisecond, MaxAttempts: 3}
+
+	// newServer returns a server that responds with the statuses in turn,
+	// and the requests it received.
+	newServer := func(t *testing.T, statuses ...int) (*httptest.Server, func() []string) {
+		var (
+			mu     sync.Mutex
+			bodies []string
+		)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			mu.Lock()
+			status := statuses[min(len(bodies), len(statuses)-1)]
+			bodies = append(bodies, r.Header.Get("Idempotency-Key")+":"+string(body))
+			mu.Unlock()
+			if status == http.StatusTooManyRequests {
+				w.Header().Set("Retry-After", "3600")
+			}
+			w.WriteHeader(status)
+		}))
+		t.Cleanup(srv.Close)
+		return srv, func() []string {
+			mu.Lock()
+			defer mu.Unlock()
+			return append([]string(nil), bodies...)
+		}
+	}
+
+	t.Run("RetriesServerErrors", func(t *testing.T) {
+		srv, requests := newServer(t, http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK)
+		var attempts, retries int
+		client := &http.Client{Transport: &Transport{
+			Policy:    policy,
+			OnAttempt: func(*http.Request, int, *http.Response, error) { attempts++ },
+			OnRetry:   func(*http.Request, int, time.Duration) { retries++ },
+		}}
+
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+		}
+		if got := len(requests()); got != 3 {
+			t.Errorf("got %d requests, want 3", got)
+		}
+		if attempts != 3 || retries != 2 {
+			t.Errorf("got %d attempts and %d retries, want 3 and 2", attempts, retries)
+		}
+	})
+
+	t.Run("ClassifiesThrottlingResponses", func(t *testing.T) {
+		var classes []backoff.ErrorClass
+		p := *policy
+		p.Observe = func(e backoff.RetryEvent) {
+			if e.Err != nil {
+				classes = append(classes, backoff.ClassifyError(e.Err))
+			}
+		}
+		statuses := []int{http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusOK}
+		client := &http.Client{Transport: &Transport{
+			Policy: &p,
+			Base: roundTripperFunc(func(*http.Request) (*http.Response, error) {
+				status := statuses[0]
+				statuses = statuses[1:]
+				return &http.Response{StatusCode: status, Header: http.Header{}, Body: http.NoBody}, nil
+			}),
+		}}
+
+		resp, err := client.Get("http://example.com")
+		if err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		resp.Body.Close()
+		if want := []backoff.ErrorClass{backoff.ClassThrottled, backoff.ClassOther}; !slices.Equal(classes, want) {
+			t.Errorf("got %v, want %v", classes, want)
+		}
+	})
+
+	t.Run("DeclinedByThrottle", func(t *testing.T) {
+		th := &backoff.AdaptiveThrottle{}
+		for range 100000 {
+			th.Allow()
+		}
+		p := *policy
+		p.Throttle = th
+		var calls int
+		client := &http.Client{Transport: &Transport{
+			Policy: &p,
+			Base: roundTripperFunc(func(*http.Request) (*http.Response, error) {
+				calls++
+				return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: http.NoBody}, nil
+			}),
+		}}
+
+		if _, err := client.Get("http://example.com"); !errors.Is(err, backoff.ErrThrottled) {
+			t.Errorf("got %v, want %v", err, backoff.ErrThrottled)
+		}
+		if calls != 0 {
+			t.Errorf("got %d calls, want 0", calls)
+		}
+	})
+
+	t.Run("ReturnsLastResponse", func(t *testing.T) {
+		srv, requests := newServer(t, http.StatusInternalServerError)
+		client := &http.Client{Transport: &Transport{Policy: policy}}
+
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusInternalServerError {
+			t.Errorf("got status %d, want %d", resp.StatusCode, http.StatusInternalServerError)
+		}
+		if got := len(requests()); got != 3 {
+			t.Errorf("got %d requests, want 3", got)
+		}
+	})
+
+	t.Run("DoesNotRetryNonIdempotent", func(t *testing.T) {
+		srv, requests := newServer(t, http.StatusServiceUnavailable, http.StatusOK)
+		client := &http.Client{Transport: &Transport{Policy: policy}}
+
+		resp, err := client.Post(srv.URL, "text/plain", strings.NewReader("body"))
+		if err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("got status %d, want %d", resp.StatusCode, http.StatusServiceUnavailable)
+		}
+		if got := len(requests()); got != 1 {
+			t.Errorf("got %d requests, want 1", got)
+		}
+	})
+
+	t.Run("GeneratesIdempotencyKey", func(t *testing.T) {
+		srv, requests := newServer(t, http.StatusServiceUnavailable, http.StatusOK)
+		p := *policy
+		p.IdempotencyKey = true
+		client := &http.Client{Transport: &Transport{Policy: &p}}
+
+		resp, err := client.Post(srv.URL, "text/plain", strings.NewReader("body"))
+		if err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		resp.Body.Close()
+		got := requests()
+		if len(got) != 2 {
+			t.Fatalf("got %d requests, want 2", len(got))
+		}
+		if got[0] != got[1] || !strings.HasSuffix(got[0], ":body") || strings.HasPrefix(got[0], ":") {
+			t.Errorf("got %q, want the same generated key on both requests", got)
+		}
+	})
+
+	t.Run("DoesNotRetryClientErrors", func(t *testing.T) {
+		srv, requests := newServer(t, http.StatusNotFound, http.StatusOK)
+		client := &http.Client{Transport: &Transport{Policy: policy}}
+
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		resp.Body.Close()
+		if got := len(requests()); got != 1 {
+			t.Errorf("got %d requests, want 1", got)
+		}
+	})
+
+	t.Run("RewindsBodyWithIdempotencyKey", func(t *testing.T) {
+		srv, requests := newServer(t, http.StatusServiceUnavailable, http.StatusOK)
+		client := &http.Client{Transport: &Transport{Policy: policy}}
+
+		ctx := backoff.WithIdempotencyKey(context.Background(), "key")
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL, strings.NewReader("body"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		resp.Body.Close()
+		got := requests()
+		if len(got) != 2 || got[0] != "key:body" || got[1] != "key:body" {
+			t.Errorf("got %q, want %q", got, []string{"key:body", "key:body"})
+		}
+		if req.Header.Get("Idempotency-Key") != "" {
+			t.Error("got Idempotency-Key set on the original request")
+		}
+	})
+
+	t.Run("RetryAfterExceedsDeadline", func(t *testing.T) {
+		srv, requests := newServer(t, http.StatusTooManyRequests, http.StatusOK)
+		client := &http.Client{Transport: &Transport{Policy: policy}}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		t.Cleanup(cancel)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusTooManyRequests {
+			t.Errorf("got status %d, want %d", resp.StatusCode, http.StatusTooManyRequests)
+		}
+		if got := len(requests()); got != 1 {
+			t.Errorf("got %d requests, want 1", got)
+		}
+	})
+
+	t.Run("RetryAfterClampedToDeadline", func(t *testing.T) {
+		srv, requests := newServer(t, http.StatusTooManyRequests, http.StatusOK)
+		var events []backoff.RetryEvent
+		client := &http.Client{Transport: &Transport{Policy: &backoff.Policy{
+			Base:            time.Millisecond,
+			Cap:             time.Millisecond,
+			MaxAttempts:     3,
+			ClampToDeadline: true,
+			DeadlineReserve: 900 * time.Millisecond,
+			Observe:         func(e backoff.RetryEvent) { events = append(events, e) },
+		}}}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		t.Cleanup(cancel)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+		}
+		if got := len(requests()); got != 2 {
+			t.Errorf("got %d requests, want 2", got)
+		}
+		if len(events) != 2 || events[0].Delay > 100*time.Millisecond || events[1].Outcome != backoff.OutcomeSuccess {
+			t.Errorf("got events %+v, want a clamped retry and a success", events)
+		}
+	})
+
+	t.Run("RetriesNetworkErrors", func(t *testing.T) {
+		var calls int
+		tr := &Transport{
+			Base: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				if calls++; calls == 1 {
+					return nil, syscall.ECONNRESET
+				}
+				return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+			}),
+			Policy: policy,
+		}
+
+		req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "http://example.com", nil)
+		resp, err := tr.RoundTrip(req)
+		if err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		if resp.StatusCode != http.StatusOK || calls != 2 {
+			t.Errorf("got status %d after %d calls, want %d after 2", resp.StatusCode, calls, http.StatusOK)
+		}
+	})
+
+	t.Run("ContextCanceledMidWait", func(t *testing.T) {
+		srv, _ := newServer(t, http.StatusServiceUnavailable)
+		client := &http.Client{Transport: &Transport{Policy: &backoff.Policy{Base: time.Hour, Cap: time.Hour}}}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.Do(req); !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want %v", err, context.Canceled)
+		}
+	})
+}
@@ -0,0 +1,68 @@
+/*
+Package backoffsim simulates the load that clients retrying under a
+[backoff.Policy] put on a server, which makes the effect of jitter on
+synchronized retries checkable rather than anecdotal.
+*/
+package backoffsim
+
+import (
+	"time"
+
+	"github.com/aofei/backoff"
+)
+
+// Arrivals is a histogram of attempts arriving at a server over time.
+type Arrivals struct {
+	// Bucket is the width of each bucket.
+	Bucket time.Duration
+
+	// Counts holds the number of attempts arriving in each bucket, where
+	// Counts[i] covers [i*Bucket, (i+1)*Bucket) since the simulation
+	// started.
+	Counts []int
+}
+
+// Total returns the total number of arrivals.
+func (a Arrivals) Total() int {
+	var total int
+	for _, c := range a.Counts {
+		total += c
+	}
+	return total
+}
+
+// Peak returns the largest number of arrivals in a single bucket.
+func (a Arrivals) Peak() int {
+	var peak int
+	for _, c := range a.Counts {
+		peak = max(peak, c)
+	}
+	return peak
+}
+
+// add records an arrival at t.
+func (a *Arrivals) add(t time.Duration) {
+	i := int(t / a.Bucket)
+	if i >= len(a.Counts) {
+		a.Counts = append(a.Counts, make([]int, i+1-len(a.Counts))...)
+	}
+	a.Counts[i]++
+}
+
+// Herd models clients that all fail at the same instant and then retry under
+// p, with every attempt failing, until they exhaust p.MaxAttempts. It returns
+// the resulting arrivals at the server in buckets of the given width. Without
+// jitter, every retry wave would arrive in a single bucket with all clients.
+//
+// Herd is a single run of a [Simulation] seeded with seed, so the same seed
+// always yields the same arrivals. It returns empty arrivals if
+// p.MaxAttempts, clients or bucket is not positive.
+func Herd(p *backoff.Policy, clients int, bucket time.Duration, seed uint64) Arrivals {
+	a := Arrivals{Bucket: bucket}
+	if p.MaxAttempts <= 0 {
+		return a
+	}
+	s := &Simulation{Policy: p, Clients: clients, Bucket: bucket, Seed: seed}
+	a.Counts = s.Run().Max
+	return a
+}
@@ -0,0 +1,56 @@
+package backoffsim
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/aofei/backoff"
+)
+
+func TestArrivals(t *testing.T) {
+	a := Arrivals{Bucket: time.Second, Counts: []int{3, 0, 5, 1}}
+	if got, want := a.Total(), 9; got != want {
+		t.Errorf("got total %d, want %d", got, want)
+	}
+	if got, want := a.Peak(), 5; got != want {
+		t.Errorf("got peak %d, want %d", got, want)
+	}
+}
+
+func TestHerd(t *testing.T) {
+	t.Run("SmoothsRetries", func(t *testing.T) {
+		p := &backoff.Policy{Base: time.Second, Cap: time.Minute, MaxAttempts: 6}
+		clients := 1000
+
+		a := Herd(p, clients, 100*time.Millisecond, 1)
+		if got, want := a.Total(), clients*p.MaxAttempts; got != want {
+			t.Errorf("got %d arrivals, want %d", got, want)
+		}
+		if got, want := a.Counts[0], clients; got < want {
+			t.Errorf("got %d arrivals in the first bucket, want >= %d", got, want)
+		}
+
+		// Without the initial wave, no bucket should see anything close
+		// to the whole herd.
+		a.Counts[0] = 0
+		if got, wantMax := a.Peak(), clients/4; got > wantMax {
+			t.Errorf("got peak %d, want <= %d", got, wantMax)
+		}
+	})
+
+	t.Run("Reproducible", func(t *testing.T) {
+		p := &backoff.Policy{Base: time.Second, Cap: time.Minute, MaxAttempts: 4}
+		a, b := Herd(p, 100, time.Second, 42), Herd(p, 100, time.Second, 42)
+		if !slices.Equal(a.Counts, b.Counts) {
+			t.Errorf("got %v and %v for the same seed", a.Counts, b.Counts)
+		}
+	})
+
+	t.Run("InvalidParameters", func(t *testing.T) {
+		p := &backoff.Policy{Base: time.Second, Cap: time.Minute}
+		if got := Herd(p, 10, time.Second, 1); got.Total() != 0 {
+			t.Errorf("got %d arrivals, want 0", got.Total())
+		}
+	})
+}
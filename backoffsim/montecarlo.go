@@ -0,0 +1,160 @@
+package backoffsim
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"github.com/aofei/backoff"
+)
+
+// FailureModel reports whether an attempt arriving at the server at the given
+// time since the simulation started fails. It draws any randomness from r.
+type FailureModel func(at time.Duration, r *rand.Rand) bool
+
+// Outage returns a [FailureModel] of a server that fails every attempt for the
+// first d and then recovers.
+func Outage(d time.Duration) FailureModel {
+	return func(at time.Duration, _ *rand.Rand) bool {
+		return at < d
+	}
+}
+
+// FailureRate returns a [FailureModel] of a server that fails each attempt
+// independently with probability rate.
+func FailureRate(rate float64) FailureModel {
+	return func(_ time.Duration, r *rand.Rand) bool {
+		return r.Float64() < rate
+	}
+}
+
+// maxClientAttempts limits the attempts of a client whose attempts are only
+// bounded by [Simulation.Horizon], so that tiny delays cannot make it take
+// practically forever to reach the horizon.
+const maxClientAttempts = 1 << 16
+
+// Simulation is a Monte Carlo simulation of many independent clients
+// executing a policy against a server with a given failure model. It lets
+// candidate policies be compared quantitatively before rollout.
+type Simulation struct {
+	// Policy is the policy executed by every client. Its MaxAttempts
+	// limits the attempts per client.
+	Policy *backoff.Policy
+
+	// Clients is the number of clients per run.
+	Clients int
+
+	// Spread, if positive, spreads the first attempts of the clients
+	// uniformly over [0, Spread). Zero means they all start at once.
+	Spread time.Duration
+
+	// Failure decides which attempts fail. If nil, every attempt fails.
+	Failure FailureModel
+
+	// Horizon, if positive, ends the simulation of a client once its next
+	// attempt would arrive after it. If Policy.MaxAttempts is not
+	// positive, a client also stops after 65536 attempts.
+	Horizon time.Duration
+
+	// Bucket is the width of the buckets of the arrival-rate curve.
+	Bucket time.Duration
+
+	// Runs is the number of independent runs to aggregate. Zero means 1.
+	Runs int
+
+	// Seed seeds the random source, so the same seed always yields the
+	// same result.
+	Seed uint64
+}
+
+// Result is the aggregate outcome of a [Simulation].
+type Result struct {
+	// Bucket is the width of each bucket.
+	Bucket time.Duration
+
+	// Mean holds the mean number of arrivals in each bucket across runs.
+	Mean []float64
+
+	// Max holds the largest number of arrivals in each bucket in any run.
+	Max []int
+
+	// SuccessRatio is the fraction of clients whose attempts eventually
+	// succeeded.
+	SuccessRatio float64
+
+	// MeanAttempts is the mean number of attempts per client.
+	MeanAttempts float64
+}
+
+// MeanRate returns the mean arrival rate, in arrivals per second, in each
+// bucket.
+func (r Result) MeanRate() []float64 {
+	rates := make([]float64, len(r.Mean))
+	for i, m := range r.Mean {
+		rates[i] = m / r.Bucket.Seconds()
+	}
+	return rates
+}
+
+// Run runs s and returns the aggregate result. It returns an empty result if
+// s.Clients or s.Bucket is not positive, or if neither s.Policy.MaxAttempts
+// nor s.Horizon bounds the attempts of a client.
+func (s *Simulation) Run() Result {
+	res := Result{Bucket: s.Bucket}
+	if s.Clients <= 0 || s.Bucket <= 0 || (s.Policy.MaxAttempts <= 0 && s.Horizon <= 0) {
+		return res
+	}
+	runs := max(s.Runs, 1)
+	failure := s.Failure
+	if failure == nil {
+		failure = func(time.Duration, *rand.Rand) bool { return true }
+	}
+
+	maxAttempts := s.Policy.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = maxClientAttempts
+	}
+
+	var successes, attempts int
+	for run := range runs {
+		r := rand.New(rand.NewPCG(s.Seed, uint64(run)))
+		p := *s.Policy
+		p.Rand = r
+
+		a := Arrivals{Bucket: s.Bucket}
+		for range s.Clients {
+			var at time.Duration
+			if s.Spread > 0 {
+				at = time.Duration(r.Int64N(int64(s.Spread)))
+			}
+			for attempt := range maxAttempts {
+				if s.Horizon > 0 && at > s.Horizon {
+					break
+				}
+				a.add(at)
+				attempts++
+				if !failure(at, r) {
+					successes++
+					break
+				}
+				at += p.Duration(attempt)
+			}
+		}
+
+		if len(a.Counts) > len(res.Mean) {
+			res.Mean = append(res.Mean, make([]float64, len(a.Counts)-len(res.Mean))...)
+			res.Max = append(res.Max, make([]int, len(a.Counts)-len(res.Max))...)
+		}
+		for i, c := range a.Counts {
+			res.Mean[i] += float64(c)
+			res.Max[i] = max(res.Max[i], c)
+		}
+	}
+
+	for i := range res.Mean {
+		res.Mean[i] /= float64(runs)
+	}
+	total := float64(runs * s.Clients)
+	res.SuccessRatio = float64(successes) / total
+	res.MeanAttempts = float64(attempts) / total
+	return res
+}
@@ -0,0 +1,129 @@
+package backoffsim
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/aofei/backoff"
+)
+
+func TestFailureModels(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+
+	t.Run("Outage", func(t *testing.T) {
+		fails := Outage(time.Minute)
+		if !fails(59*time.Second, r) {
+			t.Error("got success during outage, want failure")
+		}
+		if fails(time.Minute, r) {
+			t.Error("got failure after outage, want success")
+		}
+	})
+
+	t.Run("FailureRate", func(t *testing.T) {
+		fails := FailureRate(0.25)
+		var failures int
+		for range 10000 {
+			if fails(0, r) {
+				failures++
+			}
+		}
+		if got := float64(failures) / 10000; got < 0.23 || got > 0.27 {
+			t.Errorf("got %v, want range [0.23, 0.27]", got)
+		}
+	})
+}
+
+func TestSimulation(t *testing.T) {
+	t.Run("AllFail", func(t *testing.T) {
+		s := &Simulation{
+			Policy:  &backoff.Policy{Base: time.Second, Cap: time.Minute, MaxAttempts: 5},
+			Clients: 100,
+			Bucket:  time.Second,
+			Runs:    10,
+		}
+		res := s.Run()
+		if res.SuccessRatio != 0 {
+			t.Errorf("got success ratio %v, want 0", res.SuccessRatio)
+		}
+		if res.MeanAttempts != 5 {
+			t.Errorf("got %v mean attempts, want 5", res.MeanAttempts)
+		}
+		var total float64
+		for _, m := range res.Mean {
+			total += m
+		}
+		if want := 500.0; math.Abs(total-want) > 1e-9 {
+			t.Errorf("got %v mean arrivals, want %v", total, want)
+		}
+		if got, want := res.Max[0], 100; got < want {
+			t.Errorf("got %d arrivals in the first bucket, want >= %d", got, want)
+		}
+		if got, want := res.MeanRate()[0], res.Mean[0]; got != want {
+			t.Errorf("got rate %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Outage", func(t *testing.T) {
+		s := &Simulation{
+			Policy:  &backoff.Policy{Base: time.Second, Cap: time.Minute},
+			Clients: 100,
+			Spread:  10 * time.Second,
+			Failure: Outage(30 * time.Second),
+			Horizon: time.Hour,
+			Bucket:  time.Second,
+		}
+		res := s.Run()
+		if res.SuccessRatio != 1 {
+			t.Errorf("got success ratio %v, want 1", res.SuccessRatio)
+		}
+		if res.MeanAttempts <= 1 {
+			t.Errorf("got %v mean attempts, want > 1", res.MeanAttempts)
+		}
+	})
+
+	t.Run("Reproducible", func(t *testing.T) {
+		s := &Simulation{
+			Policy:  &backoff.Policy{Base: time.Second, Cap: time.Minute, MaxAttempts: 5},
+			Clients: 50,
+			Failure: FailureRate(0.5),
+			Bucket:  time.Second,
+			Runs:    3,
+			Seed:    42,
+		}
+		a, b := s.Run(), s.Run()
+		if !slices.Equal(a.Mean, b.Mean) || a.SuccessRatio != b.SuccessRatio {
+			t.Errorf("got different results for the same seed")
+		}
+	})
+
+	t.Run("ZeroDelays", func(t *testing.T) {
+		s := &Simulation{
+			Policy:  &backoff.Policy{Base: time.Nanosecond, Cap: time.Nanosecond},
+			Clients: 2,
+			Horizon: time.Hour,
+			Bucket:  time.Second,
+		}
+		res := s.Run()
+		if res.SuccessRatio != 0 {
+			t.Errorf("got success ratio %v, want 0", res.SuccessRatio)
+		}
+		if got, want := res.MeanAttempts, float64(maxClientAttempts); got != want {
+			t.Errorf("got %v mean attempts, want %v", got, want)
+		}
+	})
+
+	t.Run("Unbounded", func(t *testing.T) {
+		s := &Simulation{
+			Policy:  &backoff.Policy{Base: time.Second, Cap: time.Minute},
+			Clients: 10,
+			Bucket:  time.Second,
+		}
+		if res := s.Run(); len(res.Mean) != 0 {
+			t.Errorf("got %d buckets, want 0", len(res.Mean))
+		}
+	})
+}
@@ -0,0 +1,68 @@
+/*
+Package backofftest provides utilities for testing code that uses package
+backoff.
+*/
+package backofftest
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aofei/backoff"
+)
+
+// Recorder is a [backoff.Waiter] that records every requested delay instead of
+// waiting, so tests can deterministically assert which delays were scheduled.
+// Plug it into [backoff.Policy.Waiter].
+//
+// A Recorder is safe for concurrent use. The zero value is ready to use.
+type Recorder struct {
+	mu     sync.Mutex
+	delays []time.Duration
+}
+
+var _ backoff.Waiter = (*Recorder)(nil)
+
+// Wait implements [backoff.Waiter]. It records d and returns immediately with
+// ctx.Err().
+func (r *Recorder) Wait(ctx context.Context, d time.Duration) error {
+	r.mu.Lock()
+	r.delays = append(r.delays, d)
+	r.mu.Unlock()
+	return ctx.Err()
+}
+
+// Delays returns a copy of the recorded delays, in the order they were
+// requested.
+func (r *Recorder) Delays() []time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.delays)
+}
+
+// Reset discards the recorded delays.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	r.delays = nil
+	r.mu.Unlock()
+}
+
+// AssertWithinEnvelope reports a test error for every delay in delays that is
+// inconsistent with the envelope of p, where delays[n] is the delay after
+// attempt n and must lie within the bounds returned by p.DelayBounds(n). It
+// reports whether all delays are consistent.
+func AssertWithinEnvelope(t testing.TB, delays []time.Duration, p *backoff.Policy) bool {
+	t.Helper()
+	ok := true
+	for attempt, d := range delays {
+		lo, hi := p.DelayBounds(attempt)
+		if d < lo || d > hi {
+			t.Errorf("got delay %v after attempt %d, want range [%v, %v]", d, attempt, lo, hi)
+			ok = false
+		}
+	}
+	return ok
+}
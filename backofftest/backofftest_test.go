@@ -0,0 +1,107 @@
+package backofftest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aofei/backoff"
+)
+
+func TestRecorder(t *testing.T) {
+	t.Run("RecordsWithoutWaiting", func(t *testing.T) {
+		var r Recorder
+		p := &backoff.Policy{Base: time.Hour, Cap: 10 * time.Hour, MaxAttempts: 4, Waiter: &r}
+
+		startTime := time.Now()
+		for range p.Attempts(context.Background()) {
+		}
+		if elapsed, wantMax := time.Since(startTime), time.Second; elapsed > wantMax {
+			t.Errorf("got %v, want <= %v", elapsed, wantMax)
+		}
+		if got := r.Delays(); len(got) != 3 {
+			t.Errorf("got %d delays, want 3", len(got))
+		}
+	})
+
+	t.Run("ContextCanceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		var r Recorder
+		if err := r.Wait(ctx, time.Second); !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want %v", err, context.Canceled)
+		}
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		var r Recorder
+		r.Wait(context.Background(), time.Second)
+		r.Reset()
+		if got := r.Delays(); got != nil {
+			t.Errorf("got %v, want nil", got)
+		}
+	})
+}
+
+func TestAssertWithinEnvelope(t *testing.T) {
+	p := &backoff.Policy{Base: 100 * time.Millisecond, Cap: 300 * time.Millisecond}
+	for _, tt := range []struct {
+		name   string
+		delays []time.Duration
+		wantOK bool
+	}{
+		{
+			name:   "Consistent",
+			delays: []time.Duration{99 * time.Millisecond, 199 * time.Millisecond, 299 * time.Millisecond, 0},
+			wantOK: true,
+		},
+		{
+			name:   "AboveLimit",
+			delays: []time.Duration{100 * time.Millisecond},
+			wantOK: false,
+		},
+		{
+			name:   "Negative",
+			delays: []time.Duration{0, -1},
+			wantOK: false,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := &fakeTB{TB: t}
+			if got := AssertWithinEnvelope(tb, tt.delays, p); got != tt.wantOK {
+				t.Errorf("got %t, want %t", got, tt.wantOK)
+			}
+			if tb.failed == tt.wantOK {
+				t.Errorf("got failed %t, want %t", tb.failed, !tt.wantOK)
+			}
+		})
+	}
+
+	t.Run("RecordedDelays", func(t *testing.T) {
+		var r Recorder
+		p := &backoff.Policy{Base: time.Hour, Cap: 10 * time.Hour, MaxAttempts: 8, Waiter: &r}
+		for range p.Attempts(context.Background()) {
+		}
+		AssertWithinEnvelope(t, r.Delays(), p)
+	})
+}
+
+// fakeTB is a [testing.TB] that records failures instead of failing the test.
+type fakeTB struct {
+	testing.TB
+	failed bool
+}
+
+// Helper implements [testing.TB].
+func (tb *fakeTB) Helper() {}
+
+// Errorf implements [testing.TB].
+func (tb *fakeTB) Errorf(string, ...any) { tb.failed = true }
+
+// Fatalf implements [testing.TB].
+func (tb *fakeTB) Fatalf(string, ...any) {
+	tb.failed = true
+	panic("fatal")
+}
@@ -0,0 +1,64 @@
+package backofftest
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// FailFirst wraps fn so that its first n calls fail with err without calling
+// fn, and later calls are passed through to fn. The returned function is safe
+// for concurrent use.
+func FailFirst(n int, err error, fn func(ctx context.Context) error) func(ctx context.Context) error {
+	var (
+		mu    sync.Mutex
+		calls int
+	)
+	return func(ctx context.Context) error {
+		mu.Lock()
+		calls++
+		fail := calls <= n
+		mu.Unlock()
+		if fail {
+			return err
+		}
+		return fn(ctx)
+	}
+}
+
+// FailRandomly wraps fn so that each call fails with err with probability p
+// without calling fn, and is otherwise passed through to fn. The returned
+// function is safe for concurrent use.
+func FailRandomly(p float64, err error, fn func(ctx context.Context) error) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		if rand.Float64() < p {
+			return err
+		}
+		return fn(ctx)
+	}
+}
+
+// FailFor wraps fn so that every call made within d of the first call fails
+// with err without calling fn, and later calls are passed through to fn. It
+// models an outage of a fixed length. The returned function is safe for
+// concurrent use.
+func FailFor(d time.Duration, err error, fn func(ctx context.Context) error) func(ctx context.Context) error {
+	var (
+		once      sync.Once
+		startTime time.Time
+	)
+	return func(ctx context.Context) error {
+		once.Do(func() { startTime = time.Now() })
+		if time.Since(startTime) < d {
+			return err
+		}
+		return fn(ctx)
+	}
+}
+
+// Succeed is a function that always succeeds. It is a convenient innermost
+// function for the failure injectors of this package.
+func Succeed(context.Context) error {
+	return nil
+}
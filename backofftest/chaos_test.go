@@ -0,0 +1,50 @@
+package backofftest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var errInjected = errors.New("injected")
+
+func TestFailFirst(t *testing.T) {
+	fn := FailFirst(2, errInjected, Succeed)
+	for i, want := range []error{errInjected, errInjected, nil, nil} {
+		if got := fn(context.Background()); !errors.Is(got, want) {
+			t.Errorf("got %v on call %d, want %v", got, i, want)
+		}
+	}
+}
+
+func TestFailRandomly(t *testing.T) {
+	t.Run("Always", func(t *testing.T) {
+		fn := FailRandomly(1, errInjected, Succeed)
+		for range 10 {
+			if got := fn(context.Background()); !errors.Is(got, errInjected) {
+				t.Errorf("got %v, want %v", got, errInjected)
+			}
+		}
+	})
+
+	t.Run("Never", func(t *testing.T) {
+		fn := FailRandomly(0, errInjected, Succeed)
+		for range 10 {
+			if got := fn(context.Background()); got != nil {
+				t.Errorf("got %v, want nil", got)
+			}
+		}
+	})
+}
+
+func TestFailFor(t *testing.T) {
+	fn := FailFor(20*time.Millisecond, errInjected, Succeed)
+	if got := fn(context.Background()); !errors.Is(got, errInjected) {
+		t.Errorf("got %v, want %v", got, errInjected)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if got := fn(context.Background()); got != nil {
+		t.Errorf("got %v, want nil", got)
+	}
+}
@@ -0,0 +1,69 @@
+package backofftest
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aofei/backoff"
+)
+
+// UpdateGoldenEnv is the environment variable that makes [AssertGolden] write
+// golden files instead of comparing against them when set to a non-empty
+// value.
+const UpdateGoldenEnv = "BACKOFFTEST_UPDATE_GOLDEN"
+
+// AssertGolden draws n delays from p with [backoff.Policy.PlanSeed] and seed,
+// and reports a test error if they differ from the golden file at path, which
+// holds one delay per line. This catches changes to the delay algorithm, such
+// as after upgrading package backoff, in consuming repositories. It reports
+// whether the schedule matches.
+//
+// If the [UpdateGoldenEnv] environment variable is set, the golden file is
+// written instead, creating its directory if necessary.
+func AssertGolden(t testing.TB, path string, p *backoff.Policy, n int, seed uint64) bool {
+	t.Helper()
+
+	var b bytes.Buffer
+	for _, d := range p.PlanSeed(n, seed) {
+		b.WriteString(d.String())
+		b.WriteByte('\n')
+	}
+
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create golden file directory: %v", err)
+		}
+		if err := os.WriteFile(path, b.Bytes(), 0o644); err != nil {
+			t.Fatalf("failed to write golden file: %v", err)
+		}
+		return true
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file (set %s=1 to create it): %v", UpdateGoldenEnv, err)
+	}
+	if bytes.Equal(b.Bytes(), want) {
+		return true
+	}
+
+	gotLines := strings.Split(b.String(), "\n")
+	wantLines := strings.Split(string(want), "\n")
+	for i := range max(len(gotLines), len(wantLines)) {
+		var got, want string
+		if i < len(gotLines) {
+			got = gotLines[i]
+		}
+		if i < len(wantLines) {
+			want = wantLines[i]
+		}
+		if got != want {
+			t.Errorf("got %q at line %d of golden file %s, want %q", got, i+1, path, want)
+			break
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package backofftest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aofei/backoff"
+)
+
+func TestAssertGolden(t *testing.T) {
+	p := &backoff.Policy{Base: 100 * time.Millisecond, Cap: 10 * time.Second}
+	path := filepath.Join(t.TempDir(), "testdata", "schedule.golden")
+
+	t.Run("MissingFile", func(t *testing.T) {
+		tb := &fakeTB{TB: t}
+		func() {
+			defer func() { recover() }()
+			AssertGolden(tb, path, p, 8, 42)
+		}()
+		if !tb.failed {
+			t.Error("got success, want failure")
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		t.Setenv(UpdateGoldenEnv, "1")
+		if !AssertGolden(t, path, p, 8, 42) {
+			t.Error("got mismatch, want update")
+		}
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("got %v, want nil", err)
+		}
+	})
+
+	t.Run("Match", func(t *testing.T) {
+		if !AssertGolden(t, path, p, 8, 42) {
+			t.Error("got mismatch, want match")
+		}
+	})
+
+	t.Run("Mismatch", func(t *testing.T) {
+		tb := &fakeTB{TB: t}
+		if AssertGolden(tb, path, p, 8, 43) {
+			t.Error("got match, want mismatch")
+		}
+		if !tb.failed {
+			t.Error("got success, want failure")
+		}
+	})
+}
@@ -0,0 +1,23 @@
+package backoff
+
+import "time"
+
+// DailyBlackout returns a [Policy.Blackout] function for a window that recurs
+// every day at start after midnight, in the location of the checked time, and
+// lasts for length. A window may cross midnight.
+//
+//	p.Blackout = backoff.DailyBlackout(2*time.Hour, 30*time.Minute) // 02:00-02:30
+func DailyBlackout(start, length time.Duration) func(t time.Time) (end time.Time, ok bool) {
+	return func(t time.Time) (time.Time, bool) {
+		y, m, d := t.Date()
+		midnight := time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+		for _, day := range []time.Time{midnight, midnight.AddDate(0, 0, -1)} {
+			windowStart := day.Add(start)
+			windowEnd := windowStart.Add(length)
+			if !t.Before(windowStart) && t.Before(windowEnd) {
+				return windowEnd, true
+			}
+		}
+		return time.Time{}, false
+	}
+}
@@ -0,0 +1,73 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDailyBlackout(t *testing.T) {
+	day := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		name    string
+		start   time.Duration
+		length  time.Duration
+		t       time.Time
+		wantEnd time.Time
+		wantOK  bool
+	}{
+		{
+			name:    "Inside",
+			start:   2 * time.Hour,
+			length:  30 * time.Minute,
+			t:       day.Add(2*time.Hour + 10*time.Minute),
+			wantEnd: day.Add(2*time.Hour + 30*time.Minute),
+			wantOK:  true,
+		},
+		{
+			name:    "AtStart",
+			start:   2 * time.Hour,
+			length:  30 * time.Minute,
+			t:       day.Add(2 * time.Hour),
+			wantEnd: day.Add(2*time.Hour + 30*time.Minute),
+			wantOK:  true,
+		},
+		{
+			name:   "AtEnd",
+			start:  2 * time.Hour,
+			length: 30 * time.Minute,
+			t:      day.Add(2*time.Hour + 30*time.Minute),
+		},
+		{
+			name:   "Before",
+			start:  2 * time.Hour,
+			length: 30 * time.Minute,
+			t:      day.Add(time.Hour),
+		},
+		{
+			name:    "CrossesMidnightBefore",
+			start:   23 * time.Hour,
+			length:  2 * time.Hour,
+			t:       day.Add(23*time.Hour + 30*time.Minute),
+			wantEnd: day.Add(25 * time.Hour),
+			wantOK:  true,
+		},
+		{
+			name:    "CrossesMidnightAfter",
+			start:   23 * time.Hour,
+			length:  2 * time.Hour,
+			t:       day.Add(30 * time.Minute),
+			wantEnd: day.Add(time.Hour),
+			wantOK:  true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			end, ok := DailyBlackout(tt.start, tt.length)(tt.t)
+			if ok != tt.wantOK {
+				t.Fatalf("got %t, want %t", ok, tt.wantOK)
+			}
+			if !end.Equal(tt.wantEnd) {
+				t.Errorf("got %v, want %v", end, tt.wantEnd)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package backoff
+
+import (
+	"context"
+	"time"
+)
+
+// Attempt is an attempt notification delivered by [AttemptsChan].
+type Attempt struct {
+	// Number is the zero-based attempt number.
+	Number int
+
+	// Time is when the attempt became due.
+	Time time.Time
+}
+
+// AttemptsChan is like [Policy.Attempts] but delivers the attempts on a
+// channel, so that select-based state machines can consume the retry schedule
+// alongside their other events. The channel is closed once the attempts are
+// exhausted or ctx is done.
+//
+// The channel is unbuffered, and the delay before an attempt only starts once
+// the previous one has been received, so a slow consumer delays the schedule
+// rather than accumulating attempts. A consumer that stops receiving early must
+// cancel ctx to release the goroutine feeding the channel.
+func AttemptsChan(ctx context.Context, p *Policy) <-chan Attempt {
+	c := make(chan Attempt)
+	go func() {
+		defer close(c)
+		for attempt := range p.Attempts(ctx) {
+			select {
+			case c <- Attempt{Number: attempt, Time: time.Now()}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return c
+}
@@ -0,0 +1,50 @@
+package backoff
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAttemptsChan(t *testing.T) {
+	t.Run("DeliversAttemptsInOrder", func(t *testing.T) {
+		var w recordingWaiter
+		p := &Policy{Base: time.Second, Cap: time.Second, MaxAttempts: 4, Waiter: &w}
+
+		var want int
+		for a := range AttemptsChan(context.Background(), p) {
+			if a.Number != want {
+				t.Errorf("got attempt %d, want %d", a.Number, want)
+			}
+			if a.Time.IsZero() {
+				t.Error("got zero time")
+			}
+			want++
+		}
+		if want != 4 {
+			t.Errorf("got %d attempts, want 4", want)
+		}
+		if got := len(w.delays); got != 3 {
+			t.Errorf("got %d waits, want 3", got)
+		}
+	})
+
+	t.Run("ClosedWhenContextCanceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		p := &Policy{Base: time.Hour, Cap: time.Hour}
+
+		c := AttemptsChan(ctx, p)
+		if a := <-c; a.Number != 0 {
+			t.Errorf("got attempt %d, want 0", a.Number)
+		}
+		cancel()
+		select {
+		case _, ok := <-c:
+			if ok {
+				t.Error("got attempt, want closed channel")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("got timeout, want closed channel")
+		}
+	})
+}
@@ -0,0 +1,76 @@
+package backoff
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// epoch is the reference point of the monotonic timestamps kept by the
+// package. It carries a monotonic clock reading, so durations measured from it
+// are immune to wall-clock jumps such as NTP steps.
+var epoch = time.Now()
+
+// monotonicNow returns the current time as the duration since epoch, read from
+// the monotonic clock. It is always positive, so that a zero timestamp can
+// mean "unset".
+var monotonicNow = func() time.Duration {
+	return max(time.Since(epoch), 1)
+}
+
+// coarseTick is how often the coarse clock is refreshed.
+const coarseTick = time.Millisecond
+
+// coarseIdleTicks is how many ticks without a read the coarse clock keeps
+// refreshing before it stops.
+const coarseIdleTicks = 1000
+
+// coarseClock is a cached [monotonicNow] refreshed every coarseTick by a
+// background goroutine, which only runs while the clock is being read. See
+// [Policy.CoarseClock].
+type coarseClock struct {
+	now     atomic.Int64
+	idle    atomic.Int32
+	running atomic.Bool
+}
+
+// coarse is the coarse clock shared by all policies.
+var coarse coarseClock
+
+// read returns the cached time, which lags monotonicNow by less than
+// coarseTick while the clock runs.
+func (c *coarseClock) read() time.Duration {
+	c.idle.Store(0)
+	if !c.running.Load() && c.running.CompareAndSwap(false, true) {
+		c.now.Store(int64(monotonicNow()))
+		go c.run()
+	}
+	return time.Duration(c.now.Load())
+}
+
+// run refreshes c every coarseTick until it has not been read for
+// coarseIdleTicks.
+func (c *coarseClock) run() {
+	ticker := time.NewTicker(coarseTick)
+	defer ticker.Stop()
+	for range ticker.C {
+		c.now.Store(int64(monotonicNow()))
+		if c.idle.Add(1) < coarseIdleTicks {
+			continue
+		}
+		c.running.Store(false)
+		// A read may have come in after the last check, in which case
+		// the clock keeps running unless that read restarted it.
+		if c.idle.Load() != 0 || !c.running.CompareAndSwap(false, true) {
+			return
+		}
+	}
+}
+
+// monotonic returns the current time as [monotonicNow] does, or from the
+// coarse clock if p.CoarseClock is set.
+func (p *Policy) monotonic() time.Duration {
+	if p.CoarseClock {
+		return coarse.read()
+	}
+	return monotonicNow()
+}
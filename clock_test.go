@@ -0,0 +1,136 @@
+package backoff
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClock replaces monotonicNow with a clock that only advances when told
+// to, until the test ends.
+type fakeClock struct {
+	now time.Duration
+}
+
+// newFakeClock returns a new fakeClock installed for the duration of t.
+func newFakeClock(t *testing.T) *fakeClock {
+	c := &fakeClock{now: time.Hour}
+	orig := monotonicNow
+	monotonicNow = func() time.Duration { return c.now }
+	t.Cleanup(func() { monotonicNow = orig })
+	return c
+}
+
+// Wait implements [Waiter] by advancing c by d.
+func (c *fakeClock) Wait(ctx context.Context, d time.Duration) error {
+	c.now += d
+	return ctx.Err()
+}
+
+func TestEpoch(t *testing.T) {
+	// The monotonic clock reading of a time.Time shows up as "m=" in its
+	// String output.
+	if got := epoch.String(); !strings.Contains(got, " m=") {
+		t.Errorf("got %q, want a monotonic clock reading", got)
+	}
+	if got := monotonicNow(); got <= 0 {
+		t.Errorf("got %v, want > 0", got)
+	}
+}
+
+func TestMonotonicMaxElapsedTime(t *testing.T) {
+	c := newFakeClock(t)
+	p := &Policy{
+		Base:           10 * time.Minute,
+		Cap:            10 * time.Minute,
+		Jitter:         NoJitter,
+		MaxElapsedTime: 35 * time.Minute,
+		Waiter:         c,
+	}
+
+	// Only the monotonic clock advances, by the waits, so the attempts at
+	// 0, 10, 20 and 30 minutes fit but one at 40 minutes does not.
+	var got int
+	for range p.Attempts(context.Background()) {
+		got++
+	}
+	if want := 4; got != want {
+		t.Errorf("got %d attempts, want %d", got, want)
+	}
+}
+
+func TestMonotonicResetAfter(t *testing.T) {
+	c := newFakeClock(t)
+	b := &Backoff{
+		Policy:     &Policy{Base: time.Second, Cap: time.Minute, Jitter: NoJitter},
+		ResetAfter: time.Hour,
+	}
+	b.Next()
+	b.Next()
+
+	b.Success()
+	c.now += time.Hour - 1
+	if got, want := b.Next(), 4*time.Second; got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	b.Success()
+	c.now += time.Hour
+	if got, want := b.Next(), time.Second; got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got, want := b.Stats().Elapsed, time.Duration(0); got != want {
+		t.Errorf("got elapsed %v, want %v", got, want)
+	}
+}
+
+// waitCoarseClockIdle waits, when t ends, for the goroutine refreshing the
+// coarse clock to stop, since it reads monotonicNow, which later tests may
+// replace with a fake clock.
+func waitCoarseClockIdle(t *testing.T) {
+	t.Cleanup(func() {
+		for coarse.running.Load() {
+			time.Sleep(10 * coarseTick)
+		}
+	})
+}
+
+func TestCoarseClock(t *testing.T) {
+	waitCoarseClockIdle(t)
+	p := &Policy{CoarseClock: true}
+
+	start := p.monotonic()
+	if got := monotonicNow() - start; got < 0 || got > 10*coarseTick {
+		t.Errorf("got lag %v, want range [0, %v]", got, 10*coarseTick)
+	}
+	time.Sleep(20 * coarseTick)
+	if got := p.monotonic() - start; got < 10*coarseTick {
+		t.Errorf("got %v elapsed, want at least %v", got, 10*coarseTick)
+	}
+
+	for deadline := time.Now().Add(5 * time.Second); coarse.running.Load(); {
+		if time.Now().After(deadline) {
+			t.Fatal("got running coarse clock, want stopped when idle")
+		}
+		time.Sleep(10 * coarseTick)
+	}
+}
+
+func TestCoarseClockMaxElapsedTime(t *testing.T) {
+	waitCoarseClockIdle(t)
+	p := &Policy{
+		Base:           time.Millisecond,
+		Cap:            time.Millisecond,
+		Jitter:         NoJitter,
+		MaxElapsedTime: 50 * time.Millisecond,
+		CoarseClock:    true,
+	}
+	var got int
+	for range p.Attempts(context.Background()) {
+		got++
+	}
+	if got < 2 || got > 51 {
+		t.Errorf("got %d attempts, want range [2, 51]", got)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+)
+
+// runChart runs the chart command.
+func runChart(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("chart", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	p := policyFlags(fs)
+	samples := fs.Int("samples", 3, "number of sample schedules (at most 9)")
+	seed := fs.Uint64("seed", 0, "seed for the sample schedules (0 means random)")
+	width := fs.Int("width", 60, "width of the chart in columns")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	if p.MaxAttempts <= 1 {
+		return errors.New("attempts must be greater than 1")
+	}
+	if *samples < 0 || *samples > 9 {
+		return errors.New("samples must be between 0 and 9")
+	}
+	if *width < 10 {
+		return errors.New("width must be at least 10")
+	}
+
+	n := p.MaxAttempts - 1
+	schedules := make([][]time.Duration, *samples)
+	for i := range schedules {
+		if *seed != 0 {
+			schedules[i] = p.PlanSeed(n, *seed+uint64(i))
+		} else {
+			schedules[i] = p.Plan(n)
+		}
+	}
+
+	limits := p.Limits()
+	var maxLimit, maxTotal time.Duration
+	for attempt := range n {
+		maxLimit = max(maxLimit, limits.Limit(attempt))
+		maxTotal += limits.Limit(attempt)
+	}
+
+	// The envelope chart shows the range each delay is drawn from as a
+	// line of "-" and where each sample fell within it as its number.
+	fmt.Fprintf(stdout, "envelope (0 to %v)\n", maxLimit)
+	for attempt := range n {
+		row := bytes.Repeat([]byte{' '}, *width)
+		for x := range column(limits.Limit(attempt), maxLimit, *width) {
+			row[x] = '-'
+		}
+		for i, s := range schedules {
+			row[min(column(s[attempt], maxLimit, *width), *width-1)] = byte('1' + i)
+		}
+		fmt.Fprintf(stdout, "%3d |%s| %v\n", attempt, row, limits.Limit(attempt))
+	}
+
+	// The timeline chart shows when each attempt of each sample fires,
+	// relative to the first attempt, marking attempt n with n modulo 10.
+	fmt.Fprintf(stdout, "\ntimeline (0 to %v)\n", maxTotal)
+	for i, s := range schedules {
+		row := bytes.Repeat([]byte{' '}, *width)
+		var at time.Duration
+		row[0] = '0'
+		for attempt, d := range s {
+			at += d
+			row[min(column(at, maxTotal, *width), *width-1)] = byte('0' + (attempt+1)%10)
+		}
+		fmt.Fprintf(stdout, "  %d |%s| %v\n", i+1, row, round(at))
+	}
+	return nil
+}
+
+// column returns the column at which d falls on a chart of the given width
+// spanning [0, span].
+func column(d, span time.Duration, width int) int {
+	if span <= 0 {
+		return 0
+	}
+	return int(float64(d) / float64(span) * float64(width))
+}
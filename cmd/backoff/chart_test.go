@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRunChart(t *testing.T) {
+	t.Run("Seeded", func(t *testing.T) {
+		args := []string{"chart", "-base", "100ms", "-cap", "1s", "-attempts", "6", "-samples", "2", "-seed", "42", "-width", "40"}
+
+		var out bytes.Buffer
+		if err := run(args, &out, &bytes.Buffer{}); err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+
+		lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+		if got, want := len(lines), 1+5+1+1+2; got != want {
+			t.Fatalf("got %d lines, want %d:\n%s", got, want, out.String())
+		}
+		if !strings.HasPrefix(lines[0], "envelope (0 to 1s)") {
+			t.Errorf("got %q, want envelope header", lines[0])
+		}
+		for _, line := range lines[1:6] {
+			chart := line[strings.Index(line, "|")+1 : strings.LastIndex(line, "|")]
+			if len(chart) != 40 {
+				t.Errorf("got %q, want a 40-column chart", line)
+			}
+			if !strings.ContainsAny(chart, "12") {
+				t.Errorf("got %q, want samples marked", line)
+			}
+		}
+		if !strings.HasPrefix(lines[7], "timeline (0 to 2.5s)") {
+			t.Errorf("got %q, want timeline header", lines[7])
+		}
+	})
+
+	for _, tt := range []struct {
+		name string
+		args []string
+	}{
+		{"InvalidPolicy", []string{"chart", "-cap", "0"}},
+		{"TooFewAttempts", []string{"chart", "-attempts", "1"}},
+		{"TooManySamples", []string{"chart", "-samples", "10"}},
+		{"TooNarrow", []string{"chart", "-width", "9"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := run(tt.args, &bytes.Buffer{}, &bytes.Buffer{}); err == nil {
+				t.Error("got nil, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+)
+
+// errWarnings is returned by the lint command when it reports warnings.
+var errWarnings = errors.New("policy has warnings")
+
+// runLint runs the lint command.
+func runLint(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	p := policyFlags(fs)
+	fs.BoolVar(&p.ClampToDeadline, "clamp-to-deadline", false, "whether delays are clamped to the context deadline")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	warnings := p.Lint()
+	for _, w := range warnings {
+		fmt.Fprintln(stdout, w)
+	}
+	if len(warnings) > 0 {
+		return errWarnings
+	}
+	return nil
+}
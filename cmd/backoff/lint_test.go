@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRunLint(t *testing.T) {
+	t.Run("NoWarnings", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := run([]string{"lint", "-base", "100ms", "-cap", "10s", "-attempts", "8"}, &out, &bytes.Buffer{}); err != nil {
+			t.Errorf("got %v, want nil", err)
+		}
+		if out.Len() != 0 {
+			t.Errorf("got %q, want empty output", out.String())
+		}
+	})
+
+	t.Run("Warnings", func(t *testing.T) {
+		var out bytes.Buffer
+		err := run([]string{"lint", "-base", "20s", "-cap", "10s", "-attempts", "1"}, &out, &bytes.Buffer{})
+		if !errors.Is(err, errWarnings) {
+			t.Errorf("got %v, want %v", err, errWarnings)
+		}
+		for _, code := range []string{"base-exceeds-cap", "no-retries"} {
+			if !strings.Contains(out.String(), code) {
+				t.Errorf("got %q, want it to contain %q", out.String(), code)
+			}
+		}
+	})
+
+	t.Run("InvalidFlag", func(t *testing.T) {
+		if err := run([]string{"lint", "-foo"}, &bytes.Buffer{}, &bytes.Buffer{}); err == nil {
+			t.Error("got nil, want error")
+		}
+	})
+}
@@ -0,0 +1,139 @@
+/*
+Command backoff inspects jittered exponential backoff configurations, so
+operators can sanity-check them without writing a Go program.
+
+Usage:
+
+	backoff <command> [flags]
+
+The commands are:
+
+	plan	print the envelope and sample schedules of a configuration
+	chart	draw the envelope and sample schedules of a configuration
+	lint	report suspicious aspects of a configuration
+
+Run "backoff <command> -h" for the flags of a command.
+*/
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aofei/backoff"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		if errors.Is(err, errWarnings) {
+			os.Exit(1)
+		}
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "backoff:", err)
+		}
+		os.Exit(2)
+	}
+}
+
+// run runs the command described by args, writing its output to stdout and
+// its usage messages to stderr.
+func run(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(`missing command (try "backoff plan -h")`)
+	}
+	switch args[0] {
+	case "plan":
+		return runPlan(args[1:], stdout, stderr)
+	case "chart":
+		return runChart(args[1:], stdout, stderr)
+	case "lint":
+		return runLint(args[1:], stdout, stderr)
+	default:
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
+
+// policyFlags registers the flags describing a [backoff.Policy] on fs and
+// returns the policy they populate.
+func policyFlags(fs *flag.FlagSet) *backoff.Policy {
+	p := &backoff.Policy{}
+	fs.DurationVar(&p.Base, "base", 100*time.Millisecond, "base delay")
+	fs.DurationVar(&p.Cap, "cap", 10*time.Second, "maximum delay")
+	fs.IntVar(&p.MaxAttempts, "attempts", 8, "maximum number of attempts")
+	return p
+}
+
+// runPlan runs the plan command.
+func runPlan(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("plan", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	p := policyFlags(fs)
+	samples := fs.Int("samples", 3, "number of sample schedules")
+	seed := fs.Uint64("seed", 0, "seed for the sample schedules (0 means random)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	if p.MaxAttempts <= 0 {
+		return errors.New("attempts must be positive")
+	}
+	if *samples < 0 {
+		return errors.New("samples must not be negative")
+	}
+
+	n := p.MaxAttempts - 1
+	schedules := make([][]time.Duration, *samples)
+	for i := range schedules {
+		if *seed != 0 {
+			schedules[i] = p.PlanSeed(n, *seed+uint64(i))
+		} else {
+			schedules[i] = p.Plan(n)
+		}
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprint(tw, "after attempt\tlimit")
+	for i := range schedules {
+		fmt.Fprintf(tw, "\tsample %d", i+1)
+	}
+	fmt.Fprintln(tw)
+
+	limits := p.Limits()
+	var maxTotal time.Duration
+	totals := make([]time.Duration, len(schedules))
+	for attempt := range n {
+		limit := limits.Limit(attempt)
+		maxTotal += limit
+		fmt.Fprintf(tw, "%d\t%v", attempt, limit)
+		for i, s := range schedules {
+			totals[i] += s[attempt]
+			fmt.Fprintf(tw, "\t%v", round(s[attempt]))
+		}
+		fmt.Fprintln(tw)
+	}
+
+	fmt.Fprintf(tw, "total\t%v", maxTotal)
+	for _, total := range totals {
+		fmt.Fprintf(tw, "\t%v", round(total))
+	}
+	fmt.Fprintln(tw)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "\nmean total wait: %v\n", round(p.MeanTotalWait(p.MaxAttempts)))
+	fmt.Fprintf(stdout, "p99 total wait:  %v\n", round(p.TotalWaitQuantile(p.MaxAttempts, 0.99)))
+	return nil
+}
+
+// round rounds d to a precision that is readable in a table.
+func round(d time.Duration) time.Duration {
+	return d.Round(time.Microsecond)
+}
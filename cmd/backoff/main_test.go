@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	t.Run("NoCommand", func(t *testing.T) {
+		if err := run(nil, &bytes.Buffer{}, &bytes.Buffer{}); err == nil {
+			t.Error("got nil, want error")
+		}
+	})
+
+	t.Run("UnknownCommand", func(t *testing.T) {
+		if err := run([]string{"foo"}, &bytes.Buffer{}, &bytes.Buffer{}); err == nil {
+			t.Error("got nil, want error")
+		}
+	})
+}
+
+func TestRunPlan(t *testing.T) {
+	t.Run("Seeded", func(t *testing.T) {
+		args := []string{"plan", "-base", "100ms", "-cap", "1s", "-attempts", "6", "-samples", "2", "-seed", "42"}
+
+		var got, want bytes.Buffer
+		if err := run(args, &got, &bytes.Buffer{}); err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		if err := run(args, &want, &bytes.Buffer{}); err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		if got.String() != want.String() {
+			t.Errorf("got %q, want %q", got.String(), want.String())
+		}
+
+		out := got.String()
+		for _, s := range []string{"after attempt", "sample 2", "100ms", "800ms", "1s", "total", "mean total wait", "p99 total wait"} {
+			if !strings.Contains(out, s) {
+				t.Errorf("got %q, want it to contain %q", out, s)
+			}
+		}
+		if got, want := strings.Count(out, "\n"), 1+5+1+3; got != want {
+			t.Errorf("got %d lines, want %d", got, want)
+		}
+	})
+
+	t.Run("InvalidPolicy", func(t *testing.T) {
+		if err := run([]string{"plan", "-base", "0"}, &bytes.Buffer{}, &bytes.Buffer{}); err == nil {
+			t.Error("got nil, want error")
+		}
+	})
+
+	t.Run("InvalidAttempts", func(t *testing.T) {
+		if err := run([]string{"plan", "-attempts", "0"}, &bytes.Buffer{}, &bytes.Buffer{}); err == nil {
+			t.Error("got nil, want error")
+		}
+	})
+
+	t.Run("NegativeSamples", func(t *testing.T) {
+		if err := run([]string{"plan", "-samples", "-1"}, &bytes.Buffer{}, &bytes.Buffer{}); err == nil {
+			t.Error("got nil, want error")
+		}
+	})
+
+	t.Run("InvalidFlag", func(t *testing.T) {
+		if err := run([]string{"plan", "-foo"}, &bytes.Buffer{}, &bytes.Buffer{}); err == nil {
+			t.Error("got nil, want error")
+		}
+	})
+}
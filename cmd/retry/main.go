@@ -0,0 +1,157 @@
+/*
+Command retry runs a command until it succeeds, waiting between attempts with
+the jittered exponential backoff of package backoff.
+
+Usage:
+
+	retry [flags] -- command [args...]
+
+A command that exits with a non-zero code is retried, unless the code is listed
+in -stop-on, or -retry-on is set and the code is not listed in it. A command
+that exceeds -timeout is killed and retried. When the attempts are exhausted,
+retry exits with the exit code of the last attempt, or 124 if it timed out.
+
+Note that stdin is passed to every attempt, so a command consuming it may see
+it empty on later attempts.
+*/
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/aofei/backoff"
+)
+
+// exitTimeout is the exit code used for an attempt that exceeded its
+// timeout, following the convention of timeout(1).
+const exitTimeout = 124
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code, err := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	stop()
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(os.Stderr, "retry:", err)
+	}
+	os.Exit(code)
+}
+
+// run runs the command described by args, passing stdin, stdout and stderr to
+// every attempt. It returns the exit code for retry itself.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	fs := flag.NewFlagSet("retry", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	p := &backoff.Policy{}
+	fs.IntVar(&p.MaxAttempts, "max", 5, "maximum number of attempts")
+	fs.DurationVar(&p.Base, "base", time.Second, "base delay")
+	fs.DurationVar(&p.Cap, "cap", 30*time.Second, "maximum delay")
+	timeout := fs.Duration("timeout", 0, "timeout of each attempt (0 means none)")
+	var retryOn, stopOn codeSet
+	fs.Var(&retryOn, "retry-on", "comma-separated exit codes to retry (default any non-zero)")
+	fs.Var(&stopOn, "stop-on", "comma-separated exit codes never to retry")
+	quiet := fs.Bool("q", false, "do not report failed attempts")
+	if err := fs.Parse(args); err != nil {
+		return 2, err
+	}
+	if fs.NArg() == 0 {
+		return 2, errors.New("missing command")
+	}
+	if p.MaxAttempts <= 0 {
+		return 2, errors.New("max must be positive")
+	}
+	if err := p.Validate(); err != nil {
+		return 2, err
+	}
+
+	code := 0
+	for attempt := range p.Attempts(ctx) {
+		var err error
+		code, err = runAttempt(ctx, *timeout, fs.Args(), stdin, stdout, stderr)
+		if err != nil {
+			return 127, err
+		}
+		if code == 0 || stopOn.has(code) || (len(retryOn) > 0 && !retryOn.has(code)) {
+			return code, nil
+		}
+		if !*quiet {
+			fmt.Fprintf(stderr, "retry: attempt %d of %d failed with exit code %d\n", attempt+1, p.MaxAttempts, code)
+		}
+	}
+	if err := ctx.Err(); err != nil && code == 0 {
+		return 1, err
+	}
+	return code, nil
+}
+
+// runAttempt runs the command once and returns its exit code. It returns an
+// error only if the command could not be started.
+func runAttempt(ctx context.Context, timeout time.Duration, args []string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return exitTimeout, nil
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return 0, nil
+	case errors.As(err, &exitErr):
+		if code := exitErr.ExitCode(); code >= 0 {
+			return code, nil
+		}
+		return 1, nil // Killed by a signal.
+	default:
+		return 0, err
+	}
+}
+
+// codeSet is a [flag.Value] holding a comma-separated set of exit codes.
+type codeSet []int
+
+// String implements [flag.Value].
+func (s *codeSet) String() string {
+	codes := make([]string, len(*s))
+	for i, code := range *s {
+		codes[i] = strconv.Itoa(code)
+	}
+	return strings.Join(codes, ",")
+}
+
+// Set implements [flag.Value].
+func (s *codeSet) Set(value string) error {
+	for _, field := range strings.Split(value, ",") {
+		code, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return fmt.Errorf("invalid exit code %q", field)
+		}
+		*s = append(*s, code)
+	}
+	return nil
+}
+
+// has reports whether s contains code.
+func (s codeSet) has(code int) bool {
+	return slices.Contains(s, code)
+}
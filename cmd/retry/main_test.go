@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		args         []string
+		wantCode     int
+		wantErr      bool
+		wantAttempts int
+	}{
+		{
+			name:         "Success",
+			args:         []string{"--", "sh", "-c", "echo attempt"},
+			wantCode:     0,
+			wantAttempts: 1,
+		},
+		{
+			name:         "ExhaustsAttempts",
+			args:         []string{"-max", "3", "--", "sh", "-c", "echo attempt; exit 3"},
+			wantCode:     3,
+			wantAttempts: 3,
+		},
+		{
+			name:         "StopOn",
+			args:         []string{"-stop-on", "2,3", "--", "sh", "-c", "echo attempt; exit 3"},
+			wantCode:     3,
+			wantAttempts: 1,
+		},
+		{
+			name:         "RetryOnMismatch",
+			args:         []string{"-retry-on", "75", "--", "sh", "-c", "echo attempt; exit 3"},
+			wantCode:     3,
+			wantAttempts: 1,
+		},
+		{
+			name:         "RetryOnMatch",
+			args:         []string{"-max", "2", "-retry-on", "75", "--", "sh", "-c", "echo attempt; exit 75"},
+			wantCode:     75,
+			wantAttempts: 2,
+		},
+		{
+			name:         "Timeout",
+			args:         []string{"-max", "2", "-timeout", "10ms", "--", "sh", "-c", "echo attempt; exec sleep 10"},
+			wantCode:     exitTimeout,
+			wantAttempts: 2,
+		},
+		{
+			name:     "MissingCommand",
+			args:     []string{"-max", "2"},
+			wantCode: 2,
+			wantErr:  true,
+		},
+		{
+			name:     "CommandNotFound",
+			args:     []string{"--", filepath.Join(t.TempDir(), "missing")},
+			wantCode: 127,
+			wantErr:  true,
+		},
+		{
+			name:     "InvalidMax",
+			args:     []string{"-max", "0", "--", "true"},
+			wantCode: 2,
+			wantErr:  true,
+		},
+		{
+			name:     "InvalidExitCode",
+			args:     []string{"-stop-on", "x", "--", "true"},
+			wantCode: 2,
+			wantErr:  true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"-base", "1ms", "-cap", "1ms", "-q"}, tt.args...)
+			var stdout bytes.Buffer
+			code, err := run(context.Background(), args, nil, &stdout, &bytes.Buffer{})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if code != tt.wantCode {
+				t.Errorf("got exit code %d, want %d", code, tt.wantCode)
+			}
+			if got := strings.Count(stdout.String(), "attempt\n"); got != tt.wantAttempts {
+				t.Errorf("got %d attempts, want %d", got, tt.wantAttempts)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package backoff
+
+import (
+	"context"
+	"time"
+)
+
+// AttemptTimeout returns the share of remaining that the attempt may take when
+// the time remaining until a deadline is split across the attempts left under
+// p.MaxAttempts. The expected delays between those attempts are set aside
+// first, so the attempts and the waits between them together fit the deadline
+// on average. The share is at least p.MinAttemptTimeout but never more than
+// remaining.
+//
+// If p.MaxAttempts is not positive, or the attempt is the last one, the whole
+// of remaining is returned.
+func (p *Policy) AttemptTimeout(remaining time.Duration, attempt int) time.Duration {
+	if remaining <= 0 {
+		return 0
+	}
+	left := 1
+	if p.MaxAttempts > 0 && attempt >= 0 && attempt < p.MaxAttempts {
+		left = p.MaxAttempts - attempt
+	}
+	if left == 1 {
+		return remaining
+	}
+
+	var waits float64
+	for n := attempt; n < p.MaxAttempts-1; n++ {
+		lo, hi := p.DelayBounds(n)
+		waits += (float64(lo) + float64(hi) + 1) / 2
+	}
+	share := saturatingDuration(max(float64(remaining)-waits, 0) / float64(left))
+	return min(max(share, p.MinAttemptTimeout), remaining)
+}
+
+// AttemptContext returns a copy of ctx whose deadline is the share of the
+// deadline of ctx given to the attempt by [Policy.AttemptTimeout], which suits
+// request paths that must answer within a fixed budget. If ctx has no
+// deadline, the returned context only adds cancellation.
+//
+// Canceling the returned context releases resources associated with it, so
+// code should call cancel as soon as the attempt completes.
+func (p *Policy) AttemptContext(ctx context.Context, attempt int) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, p.AttemptTimeout(time.Until(deadline), attempt))
+}
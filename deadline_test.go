@@ -0,0 +1,100 @@
+package backoff
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPolicyAttemptTimeout(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		policy    Policy
+		remaining time.Duration
+		attempt   int
+		want      time.Duration
+	}{
+		{
+			name:      "SplitsAcrossAttempts",
+			policy:    Policy{Base: 100 * time.Millisecond, Cap: 100 * time.Millisecond, MaxAttempts: 3},
+			remaining: 2 * time.Second,
+			attempt:   0,
+			want:      (2*time.Second - 100*time.Millisecond) / 3,
+		},
+		{
+			name:      "LaterAttempt",
+			policy:    Policy{Base: 100 * time.Millisecond, Cap: 100 * time.Millisecond, MaxAttempts: 3},
+			remaining: time.Second,
+			attempt:   1,
+			want:      (time.Second - 50*time.Millisecond) / 2,
+		},
+		{
+			name:      "LastAttempt",
+			policy:    Policy{Base: 100 * time.Millisecond, Cap: 100 * time.Millisecond, MaxAttempts: 3},
+			remaining: time.Second,
+			attempt:   2,
+			want:      time.Second,
+		},
+		{
+			name:      "Unlimited",
+			policy:    Policy{Base: 100 * time.Millisecond, Cap: 100 * time.Millisecond},
+			remaining: time.Second,
+			attempt:   5,
+			want:      time.Second,
+		},
+		{
+			name:      "Floor",
+			policy:    Policy{Base: time.Second, Cap: time.Second, MaxAttempts: 10, MinAttemptTimeout: 200 * time.Millisecond},
+			remaining: time.Second,
+			attempt:   0,
+			want:      200 * time.Millisecond,
+		},
+		{
+			name:      "FloorExceedsRemaining",
+			policy:    Policy{Base: time.Second, Cap: time.Second, MaxAttempts: 10, MinAttemptTimeout: time.Minute},
+			remaining: time.Second,
+			attempt:   0,
+			want:      time.Second,
+		},
+		{
+			name:      "NoTimeRemaining",
+			policy:    Policy{Base: time.Second, Cap: time.Second, MaxAttempts: 10},
+			remaining: -time.Second,
+			attempt:   0,
+			want:      0,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.AttemptTimeout(tt.remaining, tt.attempt); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyAttemptContext(t *testing.T) {
+	p := &Policy{Base: time.Millisecond, Cap: time.Millisecond, MaxAttempts: 4}
+
+	t.Run("SplitsDeadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+		t.Cleanup(cancel)
+
+		attemptCtx, attemptCancel := p.AttemptContext(ctx, 0)
+		t.Cleanup(attemptCancel)
+		deadline, ok := attemptCtx.Deadline()
+		if !ok {
+			t.Fatal("got no deadline, want one")
+		}
+		if got, want := time.Until(deadline), 15*time.Minute; got > want || got < want-time.Minute {
+			t.Errorf("got %v, want about %v", got, want)
+		}
+	})
+
+	t.Run("NoDeadline", func(t *testing.T) {
+		attemptCtx, attemptCancel := p.AttemptContext(context.Background(), 0)
+		t.Cleanup(attemptCancel)
+		if _, ok := attemptCtx.Deadline(); ok {
+			t.Error("got deadline, want none")
+		}
+	})
+}
@@ -0,0 +1,145 @@
+package backoff
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+// DelayQueue holds items until their due time, such as the next-attempt time
+// computed from a [Policy], and then releases them in order of due time. It
+// uses a heap and a single timer regardless of the number of items, which
+// makes it a building block for background retry processors.
+//
+// A DelayQueue is safe for concurrent use. The zero value is ready to use.
+type DelayQueue[T any] struct {
+	mu    sync.Mutex
+	items delayHeap[T]
+	seq   uint64
+	wake  chan struct{}
+}
+
+// Push adds an item that becomes due at the given time.
+func (q *DelayQueue[T]) Push(item T, at time.Time) {
+	q.mu.Lock()
+	q.seq++
+	heap.Push(&q.items, delayItem[T]{value: item, at: at, seq: q.seq})
+	wake := q.wakeLocked()
+	q.mu.Unlock()
+
+	select {
+	case wake <- struct{}{}:
+	default:
+	}
+}
+
+// PushAfter adds an item that becomes due after d.
+func (q *DelayQueue[T]) PushAfter(item T, d time.Duration) {
+	q.Push(item, time.Now().Add(d))
+}
+
+// Len returns the number of items in q that have not been released yet.
+func (q *DelayQueue[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// Run sends each item to out once it is due, in order of due time, until ctx
+// is done, in which case it returns ctx.Err(). Items with the same due time
+// are released in the order they were pushed. Only one Run should be active
+// on a queue at a time.
+func (q *DelayQueue[T]) Run(ctx context.Context, out chan<- T) error {
+	q.mu.Lock()
+	wake := q.wakeLocked()
+	q.mu.Unlock()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	<-timer.C
+
+	for {
+		q.mu.Lock()
+		if len(q.items) == 0 {
+			q.mu.Unlock()
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-wake:
+			}
+			continue
+		}
+
+		next := q.items[0]
+		if wait := time.Until(next.at); wait > 0 {
+			q.mu.Unlock()
+			timer.Reset(wait)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-wake:
+				timer.Stop()
+			case <-timer.C:
+			}
+			continue
+		}
+		heap.Pop(&q.items)
+		q.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			q.mu.Lock()
+			heap.Push(&q.items, next)
+			q.mu.Unlock()
+			return ctx.Err()
+		case out <- next.value:
+		}
+	}
+}
+
+// wakeLocked returns the channel used to wake up [DelayQueue.Run], creating it
+// if necessary. The q.mu must be held.
+func (q *DelayQueue[T]) wakeLocked() chan struct{} {
+	if q.wake == nil {
+		q.wake = make(chan struct{}, 1)
+	}
+	return q.wake
+}
+
+// delayItem is an item held by a [DelayQueue].
+type delayItem[T any] struct {
+	value T
+	at    time.Time
+	seq   uint64
+}
+
+// delayHeap is a min-heap of [delayItem] ordered by due time and then by push
+// order. It implements [heap.Interface].
+type delayHeap[T any] []delayItem[T]
+
+// Len implements [heap.Interface].
+func (h delayHeap[T]) Len() int { return len(h) }
+
+// Less implements [heap.Interface].
+func (h delayHeap[T]) Less(i, j int) bool {
+	if h[i].at.Equal(h[j].at) {
+		return h[i].seq < h[j].seq
+	}
+	return h[i].at.Before(h[j].at)
+}
+
+// Swap implements [heap.Interface].
+func (h delayHeap[T]) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+// Push implements [heap.Interface].
+func (h *delayHeap[T]) Push(x any) { *h = append(*h, x.(delayItem[T])) }
+
+// Pop implements [heap.Interface].
+func (h *delayHeap[T]) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	old[len(old)-1] = delayItem[T]{}
+	*h = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,82 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDelayQueue(t *testing.T) {
+	t.Run("ReleasesInDueOrder", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+
+		var q DelayQueue[string]
+		now := time.Now()
+		q.Push("c", now.Add(30*time.Millisecond))
+		q.Push("a", now.Add(-time.Second))
+		q.Push("b1", now.Add(10*time.Millisecond))
+		q.Push("b2", now.Add(10*time.Millisecond))
+		if got, want := q.Len(), 4; got != want {
+			t.Errorf("got %d items, want %d", got, want)
+		}
+
+		out := make(chan string)
+		errc := make(chan error, 1)
+		go func() { errc <- q.Run(ctx, out) }()
+
+		var got []string
+		for range 4 {
+			got = append(got, <-out)
+		}
+		if want := []string{"a", "b1", "b2", "c"}; !slices.Equal(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+		if elapsed, wantMin := time.Since(now), 30*time.Millisecond; elapsed < wantMin {
+			t.Errorf("got %v, want >= %v", elapsed, wantMin)
+		}
+
+		cancel()
+		if err := <-errc; !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want %v", err, context.Canceled)
+		}
+	})
+
+	t.Run("WakesForEarlierItem", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+
+		var q DelayQueue[int]
+		q.PushAfter(1, time.Hour)
+
+		out := make(chan int)
+		go q.Run(ctx, out)
+		time.Sleep(10 * time.Millisecond)
+		q.PushAfter(2, 0)
+
+		select {
+		case got := <-out:
+			if got != 2 {
+				t.Errorf("got %d, want 2", got)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("got timeout, want earlier item")
+		}
+	})
+
+	t.Run("KeepsItemWhenContextCanceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		var q DelayQueue[int]
+		q.PushAfter(1, 0)
+		time.AfterFunc(10*time.Millisecond, cancel)
+		if err := q.Run(ctx, make(chan int)); !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want %v", err, context.Canceled)
+		}
+		if got := q.Len(); got != 1 {
+			t.Errorf("got %d items, want 1", got)
+		}
+	})
+}
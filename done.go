@@ -0,0 +1,54 @@
+package backoff
+
+import (
+	"context"
+	"iter"
+	"time"
+)
+
+// SleepDone is like [Sleep] but returns early when done is closed, for code
+// that predates context plumbing. It reports whether the full delay elapsed
+// without done being closed, so it reports false for a zero delay if done is
+// already closed. A nil done is never closed.
+func SleepDone(done <-chan struct{}, base, cap time.Duration, attempt int) bool {
+	return SleepContext(doneContext{done}, base, cap, attempt) == nil
+}
+
+// AttemptsDone is like [Attempts] but stops when done is closed instead of
+// when a context is done, for code that predates context plumbing. A nil done
+// is never closed.
+func AttemptsDone(done <-chan struct{}, maxAttempts int, base, cap time.Duration) iter.Seq[int] {
+	return Attempts(doneContext{done}, maxAttempts, base, cap)
+}
+
+// RetryDone is like [Retry] but stops when done is closed instead of when a
+// context is done, for code that predates context plumbing. If done is closed
+// before fn is first called, it returns [context.Canceled]. A nil done is
+// never closed.
+func RetryDone(done <-chan struct{}, maxAttempts int, base, cap time.Duration, fn func() error) error {
+	return Retry(doneContext{done}, maxAttempts, base, cap, func(context.Context) error { return fn() })
+}
+
+// doneContext is a [context.Context] that is canceled when done is closed.
+type doneContext struct {
+	done <-chan struct{}
+}
+
+// Deadline implements [context.Context].
+func (doneContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+
+// Done implements [context.Context].
+func (c doneContext) Done() <-chan struct{} { return c.done }
+
+// Err implements [context.Context].
+func (c doneContext) Err() error {
+	select {
+	case <-c.done:
+		return context.Canceled
+	default:
+		return nil
+	}
+}
+
+// Value implements [context.Context].
+func (doneContext) Value(any) any { return nil }
@@ -0,0 +1,121 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSleepDone(t *testing.T) {
+	t.Run("Elapses", func(t *testing.T) {
+		if !SleepDone(nil, time.Millisecond, time.Millisecond, 0) {
+			t.Error("got false, want true")
+		}
+	})
+
+	t.Run("ZeroDelay", func(t *testing.T) {
+		if !SleepDone(nil, 0, time.Second, 0) {
+			t.Error("got false, want true")
+		}
+	})
+
+	t.Run("ZeroDelayAlreadyDone", func(t *testing.T) {
+		done := make(chan struct{})
+		close(done)
+		for range 100 {
+			if SleepDone(done, 0, time.Second, 0) {
+				t.Fatal("got true, want false")
+			}
+		}
+	})
+
+	t.Run("Done", func(t *testing.T) {
+		done := make(chan struct{})
+		time.AfterFunc(10*time.Millisecond, func() { close(done) })
+		if SleepDone(done, time.Hour, time.Hour, 0) {
+			t.Error("got true, want false")
+		}
+	})
+}
+
+func TestRetryDone(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	t.Run("Succeeds", func(t *testing.T) {
+		var calls int
+		err := RetryDone(nil, 3, time.Millisecond, time.Millisecond, func() error {
+			if calls++; calls < 2 {
+				return errFailed
+			}
+			return nil
+		})
+		if err != nil {
+			t.Errorf("got %v, want nil", err)
+		}
+		if calls != 2 {
+			t.Errorf("got %d calls, want 2", calls)
+		}
+	})
+
+	t.Run("Done", func(t *testing.T) {
+		done := make(chan struct{})
+		time.AfterFunc(10*time.Millisecond, func() { close(done) })
+		var calls int
+		err := RetryDone(done, 3, time.Hour, time.Hour, func() error {
+			calls++
+			return errFailed
+		})
+		if !errors.Is(err, errFailed) {
+			t.Errorf("got %v, want %v", err, errFailed)
+		}
+		if calls != 1 {
+			t.Errorf("got %d calls, want 1", calls)
+		}
+	})
+
+	t.Run("AlreadyDone", func(t *testing.T) {
+		done := make(chan struct{})
+		close(done)
+		err := RetryDone(done, 3, time.Millisecond, time.Millisecond, func() error {
+			t.Error("got call, want none")
+			return nil
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want %v", err, context.Canceled)
+		}
+	})
+}
+
+func TestAttemptsDone(t *testing.T) {
+	t.Run("Exhausts", func(t *testing.T) {
+		var got int
+		for range AttemptsDone(nil, 3, time.Nanosecond, time.Nanosecond) {
+			got++
+		}
+		if got != 3 {
+			t.Errorf("got %d attempts, want 3", got)
+		}
+	})
+
+	t.Run("Done", func(t *testing.T) {
+		done := make(chan struct{})
+		var got int
+		for range AttemptsDone(done, 10, time.Hour, time.Hour) {
+			if got++; got == 1 {
+				time.AfterFunc(10*time.Millisecond, func() { close(done) })
+			}
+		}
+		if got != 1 {
+			t.Errorf("got %d attempts, want 1", got)
+		}
+	})
+
+	t.Run("AlreadyDone", func(t *testing.T) {
+		done := make(chan struct{})
+		close(done)
+		for range AttemptsDone(done, 10, time.Millisecond, time.Millisecond) {
+			t.Fatal("got attempt, want none")
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package backoff
+
+import (
+	"context"
+	"iter"
+	"sync"
+)
+
+// Drain makes retry loops cooperate with graceful server shutdown. Retry loops
+// iterate over [Drain.Attempts], and the shutdown path calls [Drain.Shutdown],
+// which stops the loops from scheduling further attempts and waits for those
+// in flight to finish.
+//
+// A Drain is safe for concurrent use. The zero value is ready to use.
+type Drain struct {
+	// FinalAttempt reports whether a loop that is waiting out a delay when
+	// shutdown begins makes one final immediate attempt instead of giving
+	// up right away.
+	FinalAttempt bool
+
+	mu     sync.Mutex
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// Attempts is like [Policy.Attempts] but also stops once d shuts down, after
+// one final attempt if d.FinalAttempt is set. The loop counts as in flight
+// until the iteration ends. Loops started after d has shut down make no
+// attempts at all.
+func (d *Drain) Attempts(ctx context.Context, p *Policy) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		d.mu.Lock()
+		d.init()
+		if d.ctx.Err() != nil {
+			d.mu.Unlock()
+			return
+		}
+		d.wg.Add(1)
+		d.mu.Unlock()
+		defer d.wg.Done()
+
+		loopCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		defer context.AfterFunc(d.ctx, cancel)()
+
+		next := 0
+		for attempt := range p.Attempts(loopCtx) {
+			if !yield(attempt) {
+				return
+			}
+			next = attempt + 1
+		}
+		if d.FinalAttempt && next > 0 && ctx.Err() == nil && d.ctx.Err() != nil &&
+			(p.MaxAttempts <= 0 || next < p.MaxAttempts) {
+			yield(next)
+		}
+	}
+}
+
+// Shutdown stops the loops iterating over [Drain.Attempts] from scheduling
+// further attempts and waits for them to finish, or until ctx is done, in which
+// case it returns ctx.Err().
+func (d *Drain) Shutdown(ctx context.Context) error {
+	d.mu.Lock()
+	d.init()
+	d.cancel()
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-done:
+		return nil
+	}
+}
+
+// init initializes d if necessary. The d.mu must be held.
+func (d *Drain) init() {
+	if d.ctx == nil {
+		d.ctx, d.cancel = context.WithCancel(context.Background())
+	}
+}
@@ -0,0 +1,85 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDrain(t *testing.T) {
+	p := &Policy{Base: time.Hour, Cap: time.Hour, MaxAttempts: 10}
+
+	for _, tt := range []struct {
+		name         string
+		finalAttempt bool
+		wantAttempts int
+	}{
+		{
+			name:         "StopsWaitingLoops",
+			wantAttempts: 1,
+		},
+		{
+			name:         "FinalAttempt",
+			finalAttempt: true,
+			wantAttempts: 2,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Drain{FinalAttempt: tt.finalAttempt}
+
+			first := make(chan struct{})
+			done := make(chan int)
+			go func() {
+				var attempts int
+				for range d.Attempts(context.Background(), p) {
+					if attempts++; attempts == 1 {
+						close(first)
+					}
+				}
+				done <- attempts
+			}()
+			<-first
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			t.Cleanup(cancel)
+			if err := d.Shutdown(ctx); err != nil {
+				t.Fatalf("got %v, want nil", err)
+			}
+			if got := <-done; got != tt.wantAttempts {
+				t.Errorf("got %d attempts, want %d", got, tt.wantAttempts)
+			}
+		})
+	}
+
+	t.Run("NoAttemptsAfterShutdown", func(t *testing.T) {
+		var d Drain
+		if err := d.Shutdown(context.Background()); err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		for range d.Attempts(context.Background(), p) {
+			t.Fatal("got attempt, want none")
+		}
+	})
+
+	t.Run("ShutdownTimeout", func(t *testing.T) {
+		var d Drain
+		started := make(chan struct{})
+		release := make(chan struct{})
+		t.Cleanup(func() { close(release) })
+		go func() {
+			for range d.Attempts(context.Background(), p) {
+				close(started)
+				<-release
+				break
+			}
+		}()
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		t.Cleanup(cancel)
+		if err := d.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("got %v, want %v", err, context.DeadlineExceeded)
+		}
+	})
+}
@@ -0,0 +1,49 @@
+package backoff
+
+import "time"
+
+// AttemptRecord describes a single failed attempt.
+type AttemptRecord struct {
+	// Start is when the attempt started.
+	Start time.Time
+
+	// Took is how long the attempt took.
+	Took time.Duration
+
+	// Err is the error the attempt failed with.
+	Err error
+
+	// Delay is the delay waited after the attempt. It is zero for the last
+	// attempt.
+	Delay time.Duration
+}
+
+// Exhausted describes an item whose retries are exhausted or stopped by an
+// error marked with [Permanent]. It is what dead-letter hooks receive, so that
+// dead-letter queues and alerting get the full story of a failure instead of
+// only its last error.
+type Exhausted[T any] struct {
+	// Item is the item that could not be processed.
+	Item T
+
+	// History is the attempt history of Item, in order.
+	History []AttemptRecord
+
+	// Err is the error of the last attempt.
+	Err error
+}
+
+// Attempts returns the number of attempts made.
+func (e Exhausted[T]) Attempts() int {
+	return len(e.History)
+}
+
+// Elapsed returns the time from the start of the first attempt to the end of
+// the last one, or zero if there were no attempts.
+func (e Exhausted[T]) Elapsed() time.Duration {
+	if len(e.History) == 0 {
+		return 0
+	}
+	first, last := e.History[0], e.History[len(e.History)-1]
+	return last.Start.Add(last.Took).Sub(first.Start)
+}
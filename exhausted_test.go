@@ -0,0 +1,49 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExhausted(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		name         string
+		history      []AttemptRecord
+		wantAttempts int
+		wantElapsed  time.Duration
+	}{
+		{
+			name:         "Empty",
+			wantAttempts: 0,
+			wantElapsed:  0,
+		},
+		{
+			name: "Single",
+			history: []AttemptRecord{
+				{Start: start, Took: time.Second},
+			},
+			wantAttempts: 1,
+			wantElapsed:  time.Second,
+		},
+		{
+			name: "Multiple",
+			history: []AttemptRecord{
+				{Start: start, Took: time.Second, Delay: 2 * time.Second},
+				{Start: start.Add(3 * time.Second), Took: time.Second},
+			},
+			wantAttempts: 2,
+			wantElapsed:  4 * time.Second,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			e := Exhausted[string]{Item: "item", History: tt.history}
+			if got := e.Attempts(); got != tt.wantAttempts {
+				t.Errorf("got %d, want %d", got, tt.wantAttempts)
+			}
+			if got := e.Elapsed(); got != tt.wantElapsed {
+				t.Errorf("got %v, want %v", got, tt.wantElapsed)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNoEndpoints is returned by [Failover] when it is given no endpoints.
+var ErrNoEndpoints = errors.New("backoff: no endpoints")
+
+// ErrInvalidWeights is returned by [WeightedFailover] when the weights do not
+// match the endpoints.
+var ErrInvalidWeights = errors.New("backoff: invalid endpoint weights")
+
+// Failover calls fn with the endpoints in order of preference until a call
+// succeeds, and returns the endpoint that served it.
+//
+// Each endpoint keeps its own backoff state: after the n-th consecutive failure
+// of an endpoint, it cools down for p.Duration(n-1) while the next available
+// endpoint is tried, so retry N can go to replica B while replica A recovers.
+// When every endpoint is cooling down, Failover waits for the first one to
+// become available again. The total number of calls is limited by
+// p.MaxAttempts.
+//
+// If no call succeeds, Failover returns the error [Policy.Retry] would, so an
+// error marked with [Permanent] is returned right away without trying other
+// endpoints.
+func Failover[E any](ctx context.Context, p *Policy, endpoints []E, fn func(ctx context.Context, endpoint E) error) (E, error) {
+	return failover(ctx, p, endpoints, nil, fn)
+}
+
+// WeightedFailover is like [Failover] but, instead of preferring the endpoints
+// in order, picks among the available endpoints at random in proportion to
+// their weights, where weights[i] is the weight of endpoints[i]. It suits
+// replicas of unequal capacity. It returns an error wrapping
+// [ErrInvalidWeights] without calling fn if the number of weights differs
+// from the number of endpoints or any weight is not positive.
+func WeightedFailover[E any](ctx context.Context, p *Policy, endpoints []E, weights []int, fn func(ctx context.Context, endpoint E) error) (E, error) {
+	var zero E
+	if len(weights) != len(endpoints) {
+		return zero, fmt.Errorf("%w: got %d weights for %d endpoints", ErrInvalidWeights, len(weights), len(endpoints))
+	}
+	for _, w := range weights {
+		if w <= 0 {
+			return zero, fmt.Errorf("%w: got %d", ErrInvalidWeights, w)
+		}
+	}
+	return failover(ctx, p, endpoints, weights, fn)
+}
+
+// failoverState is the backoff state of an endpoint in [Failover].
+type failoverState struct {
+	failures int
+	readyAt  time.Time
+}
+
+// failover implements [Failover] and, with non-nil weights,
+// [WeightedFailover].
+func failover[E any](ctx context.Context, p *Policy, endpoints []E, weights []int, fn func(ctx context.Context, endpoint E) error) (E, error) {
+	var zero E
+	if len(endpoints) == 0 {
+		return zero, ErrNoEndpoints
+	}
+
+	states := make([]failoverState, len(endpoints))
+	current := p.pickEndpoint(states, weights, time.Now())
+	err := p.retry(ctx, func(ctx context.Context, _ int) error {
+		return fn(ctx, endpoints[current])
+	}, func(attempt int, _ time.Duration, err error) (time.Duration, bool) {
+		s := &states[current]
+		s.failures++
+		s.readyAt = time.Now().Add(p.delay(ctx, s.failures-1, err))
+		if p.MaxAttempts > 0 && attempt+1 >= p.MaxAttempts {
+			return 0, false
+		}
+
+		now := time.Now()
+		current = p.pickEndpoint(states, weights, now)
+		return max(states[current].readyAt.Sub(now), 0), true
+	}, func() any { return endpoints[current] })
+	if err != nil {
+		return zero, err
+	}
+	return endpoints[current], nil
+}
+
+// pickEndpoint returns the endpoint to call next: the first endpoint available
+// at now if weights is nil, or one of them drawn in proportion to weights
+// otherwise. If none is available, it returns the one available first.
+func (p *Policy) pickEndpoint(states []failoverState, weights []int, now time.Time) int {
+	var total int64
+	next := 0
+	for i, s := range states {
+		if s.readyAt.After(now) {
+			if states[next].readyAt.After(now) && s.readyAt.Before(states[next].readyAt) {
+				next = i
+			}
+			continue
+		}
+		if weights == nil {
+			return i
+		}
+		total += int64(weights[i])
+	}
+	if total == 0 {
+		return next
+	}
+
+	n := randN(p.Rand, total)
+	for i, s := range states {
+		if s.readyAt.After(now) {
+			continue
+		}
+		if n -= int64(weights[i]); n < 0 {
+			return i
+		}
+	}
+	return next
+}
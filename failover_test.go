@@ -0,0 +1,176 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFailover(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	t.Run("FailsOverToNextEndpoint", func(t *testing.T) {
+		p := &Policy{Base: time.Hour, Cap: time.Hour, MaxAttempts: 3}
+
+		var calls []string
+		got, err := Failover(context.Background(), p, []string{"a", "b", "c"}, func(_ context.Context, endpoint string) error {
+			calls = append(calls, endpoint)
+			if endpoint == "a" {
+				return errFailed
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		if want := "b"; got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+		if want := []string{"a", "b"}; !slices.Equal(calls, want) {
+			t.Errorf("got %v, want %v", calls, want)
+		}
+	})
+
+	t.Run("WaitsForCoolingEndpoints", func(t *testing.T) {
+		var w recordingWaiter
+		p := &Policy{Base: time.Hour, Cap: time.Hour, MaxAttempts: 4, Waiter: &w, Replay: []time.Duration{time.Hour, time.Hour}}
+
+		var calls []string
+		got, err := Failover(context.Background(), p, []string{"a", "b"}, func(_ context.Context, endpoint string) error {
+			calls = append(calls, endpoint)
+			return errFailed
+		})
+		if !errors.Is(err, errFailed) {
+			t.Errorf("got %v, want %v", err, errFailed)
+		}
+		if got != "" {
+			t.Errorf("got %q, want zero value", got)
+		}
+		if want := []string{"a", "b", "a", "b"}; !slices.Equal(calls, want) {
+			t.Errorf("got %v, want %v", calls, want)
+		}
+		if len(w.delays) != 2 {
+			t.Errorf("got %d waits, want 2", len(w.delays))
+		}
+	})
+
+	t.Run("HonorsDelayHint", func(t *testing.T) {
+		var w recordingWaiter
+		p := &Policy{Base: time.Hour, Cap: time.Hour, MaxAttempts: 2, Hint: HintReplace, Waiter: &w}
+
+		_, err := Failover(context.Background(), p, []string{"a"}, func(context.Context, string) error {
+			return WithDelayHint(errFailed, time.Second)
+		})
+		if !errors.Is(err, errFailed) {
+			t.Errorf("got %v, want %v", err, errFailed)
+		}
+		if len(w.delays) != 1 || w.delays[0] > time.Second || w.delays[0] < time.Second-time.Millisecond {
+			t.Errorf("got %v, want about [%v]", w.delays, time.Second)
+		}
+	})
+
+	t.Run("DeadLetter", func(t *testing.T) {
+		var got []Exhausted[any]
+		p := &Policy{
+			Base:        time.Hour,
+			Cap:         time.Hour,
+			MaxAttempts: 2,
+			Waiter:      &recordingWaiter{},
+			DeadLetter:  func(_ context.Context, e Exhausted[any]) { got = append(got, e) },
+		}
+		Failover(context.Background(), p, []string{"a", "b"}, func(context.Context, string) error { return errFailed })
+		if len(got) != 1 {
+			t.Fatalf("got %d dead letters, want 1", len(got))
+		}
+		if got[0].Item != "b" {
+			t.Errorf("got item %v, want %q", got[0].Item, "b")
+		}
+		if got[0].Attempts() != 2 {
+			t.Errorf("got %d attempts, want 2", got[0].Attempts())
+		}
+	})
+
+	t.Run("Permanent", func(t *testing.T) {
+		p := &Policy{Base: time.Hour, Cap: time.Hour, MaxAttempts: 3}
+
+		var calls []string
+		_, err := Failover(context.Background(), p, []string{"a", "b"}, func(_ context.Context, endpoint string) error {
+			calls = append(calls, endpoint)
+			return Permanent(errFailed)
+		})
+		if err != errFailed {
+			t.Errorf("got %v, want %v", err, errFailed)
+		}
+		if want := []string{"a"}; !slices.Equal(calls, want) {
+			t.Errorf("got %v, want %v", calls, want)
+		}
+	})
+
+	t.Run("NoEndpoints", func(t *testing.T) {
+		p := &Policy{Base: time.Millisecond, Cap: time.Millisecond}
+		_, err := Failover(context.Background(), p, nil, func(context.Context, string) error { return nil })
+		if !errors.Is(err, ErrNoEndpoints) {
+			t.Errorf("got %v, want %v", err, ErrNoEndpoints)
+		}
+	})
+
+	t.Run("ContextCanceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		p := &Policy{Base: time.Millisecond, Cap: time.Millisecond}
+		_, err := Failover(ctx, p, []string{"a"}, func(context.Context, string) error { return nil })
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want %v", err, context.Canceled)
+		}
+	})
+}
+
+func TestWeightedFailover(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	t.Run("ProportionalToWeights", func(t *testing.T) {
+		p := &Policy{Base: time.Hour, Cap: time.Hour, MaxAttempts: 1, Rand: rand.New(rand.NewPCG(1, 2))}
+		counts := map[string]int{}
+		for range 4000 {
+			got, err := WeightedFailover(context.Background(), p, []string{"a", "b"}, []int{3, 1}, func(context.Context, string) error { return nil })
+			if err != nil {
+				t.Fatalf("got %v, want nil", err)
+			}
+			counts[got]++
+		}
+		if got := counts["a"]; got < 2800 || got > 3200 {
+			t.Errorf("got %d calls to a, want about 3000", got)
+		}
+	})
+
+	t.Run("SkipsCoolingEndpoints", func(t *testing.T) {
+		p := &Policy{Base: time.Hour, Cap: time.Hour, MaxAttempts: 2}
+		var calls []string
+		got, err := WeightedFailover(context.Background(), p, []string{"a", "b"}, []int{1, 1}, func(_ context.Context, endpoint string) error {
+			calls = append(calls, endpoint)
+			if len(calls) == 1 {
+				return errFailed
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		if len(calls) != 2 || calls[0] == calls[1] || got != calls[1] {
+			t.Errorf("got %q served after calls %v, want the other endpoint", got, calls)
+		}
+	})
+
+	t.Run("InvalidWeights", func(t *testing.T) {
+		p := &Policy{Base: time.Millisecond, Cap: time.Millisecond}
+		for _, weights := range [][]int{{1}, {1, 0}, {1, -1}} {
+			_, err := WeightedFailover(context.Background(), p, []string{"a", "b"}, weights, func(context.Context, string) error { return nil })
+			if !errors.Is(err, ErrInvalidWeights) {
+				t.Errorf("got %v for %v, want %v", err, weights, ErrInvalidWeights)
+			}
+		}
+	})
+}
@@ -0,0 +1,149 @@
+package backoff
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Overflow selects which item a [Flusher] drops when its buffer is full.
+type Overflow int
+
+// The overflow policies.
+const (
+	// DropNewest drops the item being added.
+	DropNewest Overflow = iota
+
+	// DropOldest drops the oldest buffered item to make room for the one
+	// being added.
+	DropOldest
+)
+
+// Flusher buffers items, such as telemetry or log records, and periodically
+// flushes them in batches, retrying failed flushes with backoff. It is the
+// standard loop of a resilient exporter: the buffer is bounded, so a long
+// outage of the destination costs dropped items rather than unbounded memory,
+// and the number of dropped items is reported by [Flusher.Dropped].
+//
+// A Flusher is safe for concurrent use. It must not be copied after first use.
+type Flusher[T any] struct {
+	// Policy spaces the attempts to flush a batch. Its MaxAttempts limits
+	// them, after which the batch is dropped and passed to its DeadLetter,
+	// if not nil.
+	Policy *Policy
+
+	// Flush flushes a batch of items. A non-nil error retries it.
+	Flush func(ctx context.Context, items []T) error
+
+	// MaxItems is the capacity of the buffer, which also triggers a flush
+	// when reached. Zero means 1024.
+	MaxItems int
+
+	// Overflow selects which item is dropped when the buffer is full.
+	Overflow Overflow
+
+	// Interval is the time between periodic flushes. Zero means 1 second.
+	Interval time.Duration
+
+	mu      sync.Mutex
+	items   []T
+	dropped int64
+	full    chan struct{}
+}
+
+// Add adds an item to the buffer and reports whether it was kept. If the
+// buffer is full, an item is dropped according to f.Overflow.
+func (f *Flusher[T]) Add(item T) bool {
+	maxItems := f.MaxItems
+	if maxItems <= 0 {
+		maxItems = 1024
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.items) >= maxItems {
+		f.dropped++
+		if f.Overflow != DropOldest {
+			return false
+		}
+		var zero T
+		f.items[0] = zero
+		f.items = f.items[1:]
+	}
+	f.items = append(f.items, item)
+	if len(f.items) >= maxItems {
+		select {
+		case f.fullLocked() <- struct{}{}:
+		default:
+		}
+	}
+	return true
+}
+
+// Dropped returns the number of items dropped so far, either because the
+// buffer was full or because flushing them exhausted the attempts of f.Policy.
+func (f *Flusher[T]) Dropped() int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.dropped
+}
+
+// Run flushes the buffered items every f.Interval, or as soon as the buffer is
+// full, until ctx is done, in which case it returns ctx.Err(). Items still
+// buffered then can be flushed with [Flusher.FlushAll] under a shutdown
+// context.
+func (f *Flusher[T]) Run(ctx context.Context) error {
+	interval := f.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	f.mu.Lock()
+	full := f.fullLocked()
+	f.mu.Unlock()
+
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		case <-full:
+		}
+		f.FlushAll(ctx)
+		timer.Reset(interval)
+	}
+}
+
+// FlushAll flushes the buffered items as one batch, retrying as f.Policy
+// allows. It returns the error of the last attempt if the batch was dropped.
+// See [Policy.Retry].
+func (f *Flusher[T]) FlushAll(ctx context.Context) error {
+	f.mu.Lock()
+	batch := f.items
+	f.items = nil
+	f.mu.Unlock()
+	if len(batch) == 0 {
+		return nil
+	}
+
+	err := f.Policy.retry(ctx, func(ctx context.Context, _ int) error {
+		return f.Flush(ctx, batch)
+	}, f.Policy.next(ctx), func() any { return batch })
+	if err != nil {
+		f.mu.Lock()
+		f.dropped += int64(len(batch))
+		f.mu.Unlock()
+	}
+	return err
+}
+
+// fullLocked returns the channel signaling that the buffer is full, creating it
+// if necessary. The f.mu must be held.
+func (f *Flusher[T]) fullLocked() chan struct{} {
+	if f.full == nil {
+		f.full = make(chan struct{}, 1)
+	}
+	return f.full
+}
@@ -0,0 +1,158 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFlusher(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	t.Run("Overflow", func(t *testing.T) {
+		for _, tt := range []struct {
+			name     string
+			overflow Overflow
+			wantKept []int
+		}{
+			{
+				name:     "DropNewest",
+				overflow: DropNewest,
+				wantKept: []int{0, 1, 2},
+			},
+			{
+				name:     "DropOldest",
+				overflow: DropOldest,
+				wantKept: []int{2, 3, 4},
+			},
+		} {
+			t.Run(tt.name, func(t *testing.T) {
+				var flushed []int
+				f := &Flusher[int]{
+					Policy:   &Policy{Base: time.Millisecond, Cap: time.Millisecond, MaxAttempts: 1},
+					Flush:    func(_ context.Context, items []int) error { flushed = items; return nil },
+					MaxItems: 3,
+					Overflow: tt.overflow,
+				}
+				for i := range 5 {
+					f.Add(i)
+				}
+				if got, want := f.Dropped(), int64(2); got != want {
+					t.Errorf("got %d dropped, want %d", got, want)
+				}
+				if err := f.FlushAll(context.Background()); err != nil {
+					t.Fatalf("got %v, want nil", err)
+				}
+				if !slices.Equal(flushed, tt.wantKept) {
+					t.Errorf("got %v, want %v", flushed, tt.wantKept)
+				}
+			})
+		}
+	})
+
+	t.Run("RetriesAndDrops", func(t *testing.T) {
+		var w recordingWaiter
+		var (
+			calls       int
+			deadLetters []Exhausted[any]
+		)
+		f := &Flusher[string]{
+			Policy: &Policy{
+				Base:        time.Second,
+				Cap:         time.Second,
+				MaxAttempts: 3,
+				Waiter:      &w,
+				DeadLetter:  func(_ context.Context, e Exhausted[any]) { deadLetters = append(deadLetters, e) },
+			},
+			Flush: func(context.Context, []string) error {
+				calls++
+				return errFailed
+			},
+		}
+		f.Add("a")
+		f.Add("b")
+		if err := f.FlushAll(context.Background()); !errors.Is(err, errFailed) {
+			t.Errorf("got %v, want %v", err, errFailed)
+		}
+		if calls != 3 {
+			t.Errorf("got %d calls, want 3", calls)
+		}
+		if got, want := f.Dropped(), int64(2); got != want {
+			t.Errorf("got %d dropped, want %d", got, want)
+		}
+		if len(deadLetters) != 1 {
+			t.Fatalf("got %d dead letters, want 1", len(deadLetters))
+		}
+		if got, want := deadLetters[0].Item, []string{"a", "b"}; !slices.Equal(got.([]string), want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+		if got, want := deadLetters[0].Attempts(), 3; got != want {
+			t.Errorf("got %d attempts, want %d", got, want)
+		}
+	})
+
+	t.Run("RunFlushesWhenFull", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+
+		var mu sync.Mutex
+		var batches [][]int
+		flushed := make(chan struct{}, 10)
+		f := &Flusher[int]{
+			Policy: &Policy{Base: time.Millisecond, Cap: time.Millisecond, MaxAttempts: 1},
+			Flush: func(_ context.Context, items []int) error {
+				mu.Lock()
+				batches = append(batches, items)
+				mu.Unlock()
+				flushed <- struct{}{}
+				return nil
+			},
+			MaxItems: 2,
+			Interval: time.Hour,
+		}
+		errc := make(chan error, 1)
+		go func() { errc <- f.Run(ctx) }()
+
+		f.Add(1)
+		f.Add(2)
+		select {
+		case <-flushed:
+		case <-time.After(time.Second):
+			t.Fatal("got timeout, want flush")
+		}
+		cancel()
+		if err := <-errc; !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want %v", err, context.Canceled)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if len(batches) != 1 || !slices.Equal(batches[0], []int{1, 2}) {
+			t.Errorf("got %v, want [[1 2]]", batches)
+		}
+	})
+
+	t.Run("RunFlushesPeriodically", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+
+		flushed := make(chan []int, 1)
+		f := &Flusher[int]{
+			Policy:   &Policy{Base: time.Millisecond, Cap: time.Millisecond, MaxAttempts: 1},
+			Flush:    func(_ context.Context, items []int) error { flushed <- items; return nil },
+			Interval: 10 * time.Millisecond,
+		}
+		f.Add(1)
+		go f.Run(ctx)
+		select {
+		case got := <-flushed:
+			if !slices.Equal(got, []int{1}) {
+				t.Errorf("got %v, want [1]", got)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("got timeout, want flush")
+		}
+	})
+}
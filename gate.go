@@ -0,0 +1,96 @@
+package backoff
+
+import (
+	"sync"
+	"time"
+)
+
+// gateBuckets is the number of buckets the sliding window of a [Gate] is
+// divided into.
+const gateBuckets = 10
+
+// Gate suppresses retries while a dependency appears to be down, judged by the
+// success ratio of recent calls over a sliding window. Callers record the
+// outcome of every call with [Gate.Record] and consult [Gate.Allow] before
+// retrying, failing fast when it reports false. As failures age out of the
+// window and first attempts start succeeding again, retries resume.
+//
+// A Gate is safe for concurrent use. The zero value is ready to use.
+type Gate struct {
+	// Window is the length of the sliding window. Zero means 10 seconds.
+	Window time.Duration
+
+	// MinSuccessRatio is the success ratio below which retries are
+	// suppressed. Zero means 0.1.
+	MinSuccessRatio float64
+
+	// MinCalls is the minimum number of calls in the window before
+	// retries can be suppressed. Zero means 10.
+	MinCalls int
+
+	mu      sync.Mutex
+	buckets [gateBuckets]gateBucket
+}
+
+// gateBucket counts the calls recorded by a [Gate] in one slice of its window.
+type gateBucket struct {
+	start     time.Time
+	calls     int
+	successes int
+}
+
+// Record records the outcome of a call, where a nil err means success.
+func (g *Gate) Record(err error) {
+	now := time.Now()
+	width := max(g.window()/gateBuckets, 1)
+	start := now.Truncate(width)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	b := &g.buckets[start.UnixNano()/int64(width)%gateBuckets]
+	if !b.start.Equal(start) {
+		*b = gateBucket{start: start}
+	}
+	b.calls++
+	if err == nil {
+		b.successes++
+	}
+}
+
+// Allow reports whether a retry should be attempted now.
+func (g *Gate) Allow() bool {
+	calls, successes := g.counts()
+	minCalls := g.MinCalls
+	if minCalls <= 0 {
+		minCalls = 10
+	}
+	minSuccessRatio := g.MinSuccessRatio
+	if minSuccessRatio <= 0 {
+		minSuccessRatio = 0.1
+	}
+	return calls < minCalls || float64(successes)/float64(calls) >= minSuccessRatio
+}
+
+// counts returns the number of calls and successes recorded within the
+// window.
+func (g *Gate) counts() (calls, successes int) {
+	oldest := time.Now().Add(-g.window())
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, b := range g.buckets {
+		if b.start.After(oldest) {
+			calls += b.calls
+			successes += b.successes
+		}
+	}
+	return calls, successes
+}
+
+// window returns the effective length of the sliding window.
+func (g *Gate) window() time.Duration {
+	if g.Window > 0 {
+		return g.Window
+	}
+	return 10 * time.Second
+}
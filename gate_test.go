@@ -0,0 +1,81 @@
+package backoff
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGate(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	t.Run("AllowsBelowMinCalls", func(t *testing.T) {
+		var g Gate
+		for range 9 {
+			g.Record(errFailed)
+		}
+		if !g.Allow() {
+			t.Error("got false, want true")
+		}
+	})
+
+	t.Run("SuppressesWhenDown", func(t *testing.T) {
+		var g Gate
+		for range 10 {
+			g.Record(errFailed)
+		}
+		if g.Allow() {
+			t.Error("got true, want false")
+		}
+	})
+
+	t.Run("AllowsWhenHealthy", func(t *testing.T) {
+		g := Gate{MinSuccessRatio: 0.5, MinCalls: 4}
+		for range 2 {
+			g.Record(errFailed)
+			g.Record(nil)
+		}
+		if !g.Allow() {
+			t.Error("got false, want true")
+		}
+	})
+
+	t.Run("ResumesAfterWindow", func(t *testing.T) {
+		g := Gate{Window: 50 * time.Millisecond, MinCalls: 1}
+		g.Record(errFailed)
+		if g.Allow() {
+			t.Fatal("got true, want false")
+		}
+		time.Sleep(60 * time.Millisecond)
+		if !g.Allow() {
+			t.Error("got false, want true")
+		}
+	})
+
+	t.Run("TinyWindow", func(t *testing.T) {
+		g := Gate{Window: 5 * time.Nanosecond, MinCalls: 1}
+		g.Record(errFailed)
+		g.Record(nil)
+		g.Allow()
+	})
+
+	t.Run("ConcurrentUse", func(t *testing.T) {
+		var g Gate
+		var wg sync.WaitGroup
+		for range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for range 100 {
+					g.Record(nil)
+					g.Allow()
+				}
+			}()
+		}
+		wg.Wait()
+		if calls, successes := g.counts(); calls != 800 || successes != 800 {
+			t.Errorf("got %d calls and %d successes, want 800 and 800", calls, successes)
+		}
+	})
+}
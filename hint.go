@@ -0,0 +1,39 @@
+package backoff
+
+import (
+	"errors"
+	"time"
+)
+
+// hintError is an error that carries a delay hint.
+type hintError struct {
+	err  error
+	hint time.Duration
+}
+
+// Error implements [error].
+func (e *hintError) Error() string { return e.err.Error() }
+
+// Unwrap returns the wrapped error.
+func (e *hintError) Unwrap() error { return e.err }
+
+// WithDelayHint wraps err to carry a delay hint, such as one from a
+// Retry-After header. [Policy.Retry], [Pool], [Failover], [Resubscribe],
+// [RenewLease] and [Probe] honor the hint of the error an attempt failed with
+// according to [Policy.Hint], as [Policy.DurationHint] does. WithDelayHint
+// returns nil if err is nil.
+func WithDelayHint(err error, hint time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &hintError{err: err, hint: hint}
+}
+
+// delayHint returns the delay hint carried by err, or zero if there is none.
+func delayHint(err error) time.Duration {
+	var he *hintError
+	if errors.As(err, &he) {
+		return he.hint
+	}
+	return 0
+}
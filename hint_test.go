@@ -0,0 +1,28 @@
+package backoff
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithDelayHint(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	err := WithDelayHint(errFailed, time.Second)
+	if !errors.Is(err, errFailed) {
+		t.Errorf("got %v, want %v", err, errFailed)
+	}
+	if got := err.Error(); got != errFailed.Error() {
+		t.Errorf("got %q, want %q", got, errFailed.Error())
+	}
+	if got := delayHint(Permanent(err)); got != time.Second {
+		t.Errorf("got %v, want %v", got, time.Second)
+	}
+	if got := delayHint(errFailed); got != 0 {
+		t.Errorf("got %v, want 0", got)
+	}
+	if err := WithDelayHint(nil, time.Second); err != nil {
+		t.Errorf("got %v, want nil", err)
+	}
+}
@@ -0,0 +1,43 @@
+package backoff
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// idempotencyKeyContextKey is the context key of the idempotency key carried
+// by a context.
+type idempotencyKeyContextKey struct{}
+
+// NewIdempotencyKey returns a new random idempotency key of 32 hexadecimal
+// digits.
+func NewIdempotencyKey() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// WithIdempotencyKey returns a copy of ctx that carries key, or a new key from
+// [NewIdempotencyKey] if key is empty. Deriving the context of every attempt
+// of a retried write from the returned one exposes the same key to all of
+// them, so downstream services can deduplicate the retries:
+//
+//	ctx = backoff.WithIdempotencyKey(ctx, "")
+//	for range p.Attempts(ctx) {
+//		// ...
+//	}
+//
+// [Policy.Retry] does this by itself if the policy sets IdempotencyKey.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	if key == "" {
+		key = NewIdempotencyKey()
+	}
+	return context.WithValue(ctx, idempotencyKeyContextKey{}, key)
+}
+
+// IdempotencyKey returns the idempotency key carried by ctx, if any.
+func IdempotencyKey(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(idempotencyKeyContextKey{}).(string)
+	return key, ok
+}
@@ -0,0 +1,82 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewIdempotencyKey(t *testing.T) {
+	a, b := NewIdempotencyKey(), NewIdempotencyKey()
+	if len(a) != 32 {
+		t.Errorf("got length %d, want 32", len(a))
+	}
+	if a == b {
+		t.Errorf("got identical keys %q", a)
+	}
+}
+
+func TestPolicyIdempotencyKey(t *testing.T) {
+	t.Run("Generated", func(t *testing.T) {
+		p := &Policy{Base: time.Millisecond, Cap: time.Millisecond, MaxAttempts: 3, IdempotencyKey: true}
+		var keys []string
+		p.Retry(context.Background(), func(ctx context.Context) error {
+			key, _ := IdempotencyKey(ctx)
+			keys = append(keys, key)
+			return errors.New("failed")
+		})
+		if len(keys) != 3 || keys[0] == "" || keys[1] != keys[0] || keys[2] != keys[0] {
+			t.Errorf("got %q, want the same generated key 3 times", keys)
+		}
+	})
+
+	t.Run("Kept", func(t *testing.T) {
+		p := &Policy{IdempotencyKey: true}
+		ctx := WithIdempotencyKey(context.Background(), "key")
+		p.Retry(ctx, func(ctx context.Context) error {
+			if got, _ := IdempotencyKey(ctx); got != "key" {
+				t.Errorf("got %q, want %q", got, "key")
+			}
+			return nil
+		})
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		p := &Policy{}
+		p.Retry(context.Background(), func(ctx context.Context) error {
+			if _, ok := IdempotencyKey(ctx); ok {
+				t.Error("got key, want none")
+			}
+			return nil
+		})
+	})
+}
+
+func TestWithIdempotencyKey(t *testing.T) {
+	t.Run("Missing", func(t *testing.T) {
+		if key, ok := IdempotencyKey(context.Background()); ok {
+			t.Errorf("got %q, want none", key)
+		}
+	})
+
+	t.Run("Given", func(t *testing.T) {
+		ctx := WithIdempotencyKey(context.Background(), "key")
+		if key, ok := IdempotencyKey(ctx); !ok || key != "key" {
+			t.Errorf("got (%q, %t), want (%q, true)", key, ok, "key")
+		}
+	})
+
+	t.Run("Generated", func(t *testing.T) {
+		ctx := WithIdempotencyKey(context.Background(), "")
+		key, ok := IdempotencyKey(ctx)
+		if !ok || len(key) != 32 {
+			t.Errorf("got (%q, %t), want generated key", key, ok)
+		}
+		for range (&Policy{Base: 1, Cap: 1, MaxAttempts: 3}).Attempts(ctx) {
+			if got, _ := IdempotencyKey(ctx); got != key {
+				t.Errorf("got %q, want %q", got, key)
+			}
+		}
+	})
+}
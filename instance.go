@@ -0,0 +1,32 @@
+package backoff
+
+import (
+	"hash/fnv"
+	"math/rand/v2"
+	"os"
+)
+
+// InstanceRand returns a random source seeded from a hash of id, a stable
+// identity of the running instance such as its hostname or pod name, for use
+// as [Policy.Rand]. The schedule of a given instance is then reproducible
+// across restarts for debugging, while instances with different identities
+// still draw decorrelated delays.
+//
+// Like any [rand.Rand], the returned source is not safe for concurrent use
+// outside of the policies it is set on.
+func InstanceRand(id string) *rand.Rand {
+	h := fnv.New64a()
+	h.Write([]byte(id))
+	seed := h.Sum64()
+	return rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))
+}
+
+// HostRand is like [InstanceRand] but uses the hostname reported by the
+// kernel as the identity, which is also the pod name on Kubernetes.
+func HostRand() (*rand.Rand, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+	return InstanceRand(hostname), nil
+}
@@ -0,0 +1,36 @@
+package backoff
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestInstanceRand(t *testing.T) {
+	plan := func(id string) []time.Duration {
+		p := &Policy{Base: time.Millisecond, Cap: time.Minute, Rand: InstanceRand(id)}
+		return p.Plan(10)
+	}
+
+	t.Run("StableForSameIdentity", func(t *testing.T) {
+		if got, want := plan("pod-a"), plan("pod-a"); !slices.Equal(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("DecorrelatedAcrossIdentities", func(t *testing.T) {
+		if a, b := plan("pod-a"), plan("pod-b"); slices.Equal(a, b) {
+			t.Errorf("got identical plans %v for different identities", a)
+		}
+	})
+}
+
+func TestHostRand(t *testing.T) {
+	r, err := HostRand()
+	if err != nil {
+		t.Skipf("hostname unavailable: %v", err)
+	}
+	if r == nil {
+		t.Fatal("got nil, want random source")
+	}
+}
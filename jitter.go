@@ -0,0 +1,105 @@
+package backoff
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Jitter selects how a delay is randomized within its limit, that is,
+// min(cap, base*2^attempt).
+type Jitter int
+
+// The jitter strategies. See the AWS Architecture Blog post "Exponential
+// Backoff and Jitter" for how they compare. Decorrelated Jitter depends on the
+// previous delay rather than on the attempt, so it is not a Jitter but offered
+// by [DurationDecorrelated] and [Backoff].
+const (
+	// FullJitter draws the delay uniformly from [0, limit). It spreads
+	// retries the most and is the default.
+	FullJitter Jitter = iota
+
+	// EqualJitter draws the delay uniformly from [limit/2, limit), which
+	// keeps at least half of the backoff while still spreading retries.
+	EqualJitter
+
+	// NoJitter uses the limit itself as the delay. Clients that failed
+	// together then retry together, so it is mainly useful in tests.
+	NoJitter
+)
+
+// String returns the name of j.
+func (j Jitter) String() string {
+	switch j {
+	case FullJitter:
+		return "full"
+	case EqualJitter:
+		return "equal"
+	case NoJitter:
+		return "none"
+	}
+	return "Jitter(" + strconv.Itoa(int(j)) + ")"
+}
+
+// DurationWithJitter is like [Duration] but randomizes the delay with the
+// given jitter strategy instead of Full Jitter. It returns 0 for invalid
+// parameters, including an unknown jitter strategy.
+func DurationWithJitter(base, cap time.Duration, attempt int, jitter Jitter) time.Duration {
+	if base <= 0 || cap <= 0 || attempt < 0 {
+		checkStrict(base, cap, attempt)
+		return 0
+	}
+	if err := validateJitter(jitter); err != nil {
+		if strict {
+			panic(err)
+		}
+		return 0
+	}
+	lo, hi := jitterBounds(jitter, limitNanos(int64(base), int64(cap), attempt))
+	return time.Duration(lo + randN(nil, hi-lo+1))
+}
+
+// DurationDecorrelated returns a delay drawn with Decorrelated Jitter from
+// [base, min(cap, 3*prev)), where prev is the previous delay returned by
+// DurationDecorrelated, or base for the first delay. Unlike the other
+// strategies, it depends on the previous delay rather than on the attempt.
+func DurationDecorrelated(base, cap, prev time.Duration) time.Duration {
+	if base <= 0 || cap <= 0 {
+		checkStrict(base, cap, 0)
+		return 0
+	}
+	lo, hi := decorrelatedBounds(int64(base), int64(cap), int64(prev))
+	return time.Duration(lo + randN(nil, hi-lo+1))
+}
+
+// jitterBounds returns the inclusive bounds, in nanoseconds, of the delay drawn
+// with the jitter strategy for the given limit.
+func jitterBounds(jitter Jitter, limit int64) (lo, hi int64) {
+	switch jitter {
+	case EqualJitter:
+		return limit / 2, max(limit-1, limit/2)
+	case NoJitter:
+		return limit, limit
+	default:
+		return 0, max(limit-1, 0)
+	}
+}
+
+// decorrelatedBounds returns the inclusive bounds, in nanoseconds, of the delay
+// drawn with Decorrelated Jitter after a delay of prev, where a prev below
+// base stands for base.
+func decorrelatedBounds(base, cap, prev int64) (lo, hi int64) {
+	lo, hi = min(base, cap), cap
+	if prev = max(prev, base); prev <= cap/3 {
+		hi = 3 * prev
+	}
+	return lo, max(hi-1, lo)
+}
+
+// validateJitter reports whether j is a known jitter strategy.
+func validateJitter(j Jitter) error {
+	if j < FullJitter || j > NoJitter {
+		return fmt.Errorf("%w: got %v", ErrInvalidJitter, j)
+	}
+	return nil
+}
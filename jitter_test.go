@@ -0,0 +1,106 @@
+package backoff
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDurationWithJitter(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		jitter  Jitter
+		attempt int
+		wantMin time.Duration
+		wantMax time.Duration
+	}{
+		{
+			name:    "Full",
+			jitter:  FullJitter,
+			attempt: 2,
+			wantMin: 0,
+			wantMax: 400*time.Millisecond - 1,
+		},
+		{
+			name:    "Equal",
+			jitter:  EqualJitter,
+			attempt: 2,
+			wantMin: 200 * time.Millisecond,
+			wantMax: 400*time.Millisecond - 1,
+		},
+		{
+			name:    "None",
+			jitter:  NoJitter,
+			attempt: 2,
+			wantMin: 400 * time.Millisecond,
+			wantMax: 400 * time.Millisecond,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			for range 100 {
+				got := DurationWithJitter(100*time.Millisecond, time.Second, tt.attempt, tt.jitter)
+				if got < tt.wantMin || got > tt.wantMax {
+					t.Fatalf("got %v, want range [%v, %v]", got, tt.wantMin, tt.wantMax)
+				}
+			}
+
+			p := &Policy{Base: 100 * time.Millisecond, Cap: time.Second, Jitter: tt.jitter}
+			if lo, hi := p.DelayBounds(tt.attempt); lo != tt.wantMin || hi != tt.wantMax {
+				t.Errorf("got bounds [%v, %v], want [%v, %v]", lo, hi, tt.wantMin, tt.wantMax)
+			}
+		})
+	}
+
+	t.Run("Invalid", func(t *testing.T) {
+		if got := DurationWithJitter(0, time.Second, 0, EqualJitter); got != 0 {
+			t.Errorf("got %v, want 0", got)
+		}
+		if got := DurationWithJitter(time.Second, time.Second, 0, Jitter(7)); got != 0 {
+			t.Errorf("got %v, want 0", got)
+		}
+	})
+}
+
+func TestDurationDecorrelated(t *testing.T) {
+	base, cap := 100*time.Millisecond, time.Second
+	prev := base
+	for range 100 {
+		wantMax := min(cap, 3*prev)
+		d := DurationDecorrelated(base, cap, prev)
+		if d < base || d >= wantMax {
+			t.Fatalf("got %v, want range [%v, %v)", d, base, wantMax)
+		}
+		prev = d
+	}
+
+	if got := DurationDecorrelated(base, cap, 0); got < base || got >= 3*base {
+		t.Errorf("got %v, want range [%v, %v)", got, base, 3*base)
+	}
+	if got := DurationDecorrelated(0, cap, base); got != 0 {
+		t.Errorf("got %v, want 0", got)
+	}
+}
+
+func TestPolicyJitter(t *testing.T) {
+	p := &Policy{Base: 100 * time.Millisecond, Cap: time.Second, MaxAttempts: 4, Jitter: NoJitter}
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond}
+	if got := p.Schedule(nil); !slices.Equal([]time.Duration(got), want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got, want := p.MeanTotalWait(4), 700*time.Millisecond; got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestJitterString(t *testing.T) {
+	for j, want := range map[Jitter]string{
+		FullJitter:  "full",
+		EqualJitter: "equal",
+		NoJitter:    "none",
+		Jitter(42):  "Jitter(42)",
+	} {
+		if got := j.String(); got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package backoff
+
+import (
+	"sync"
+	"time"
+)
+
+// LatencyEWMA tracks an exponentially weighted moving average of the observed
+// latencies of an operation. Plug it into [Policy.Latency] so that retries
+// against a slow but alive dependency wait proportionally longer than against
+// a fast one.
+//
+// A LatencyEWMA is safe for concurrent use. The zero value is ready to use.
+type LatencyEWMA struct {
+	// Reference is the latency at or below which delays are not scaled.
+	// Zero means 100 milliseconds.
+	Reference time.Duration
+
+	// Weight is the weight of each new observation, in (0, 1]. Zero means
+	// 0.2.
+	Weight float64
+
+	mu      sync.Mutex
+	average float64
+	sampled bool
+}
+
+// Observe records the latency of a completed operation.
+func (l *LatencyEWMA) Observe(latency time.Duration) {
+	weight := l.Weight
+	if weight <= 0 || weight > 1 {
+		weight = 0.2
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.sampled {
+		l.average, l.sampled = float64(latency), true
+		return
+	}
+	l.average += weight * (float64(latency) - l.average)
+}
+
+// Average returns the current average latency, or zero if no latency has been
+// observed.
+func (l *LatencyEWMA) Average() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return saturatingDuration(l.average)
+}
+
+// Factor returns the factor by which the base of a policy is scaled, that is,
+// the average latency relative to l.Reference, but at least 1.
+func (l *LatencyEWMA) Factor() float64 {
+	reference := l.Reference
+	if reference <= 0 {
+		reference = 100 * time.Millisecond
+	}
+	return max(float64(l.Average())/float64(reference), 1)
+}
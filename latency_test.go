@@ -0,0 +1,73 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencyEWMA(t *testing.T) {
+	t.Run("Average", func(t *testing.T) {
+		var l LatencyEWMA
+		if got := l.Average(); got != 0 {
+			t.Errorf("got %v, want 0", got)
+		}
+		l.Observe(100 * time.Millisecond)
+		if got, want := l.Average(), 100*time.Millisecond; got != want {
+			t.Errorf("got %v, want %v", got, want)
+		}
+		l.Observe(600 * time.Millisecond)
+		if got, want := l.Average(), 200*time.Millisecond; got != want {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Factor", func(t *testing.T) {
+		for _, tt := range []struct {
+			name    string
+			latency time.Duration
+			want    float64
+		}{
+			{
+				name:    "Fast",
+				latency: 10 * time.Millisecond,
+				want:    1,
+			},
+			{
+				name:    "Reference",
+				latency: 100 * time.Millisecond,
+				want:    1,
+			},
+			{
+				name:    "Slow",
+				latency: time.Second,
+				want:    10,
+			},
+		} {
+			t.Run(tt.name, func(t *testing.T) {
+				var l LatencyEWMA
+				l.Observe(tt.latency)
+				if got := l.Factor(); got != tt.want {
+					t.Errorf("got %v, want %v", got, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("ScalesPolicy", func(t *testing.T) {
+		l := &LatencyEWMA{Reference: 10 * time.Millisecond}
+		l.Observe(time.Second)
+		p := &Policy{Base: time.Millisecond, Cap: 50 * time.Millisecond, Latency: l}
+
+		var sawAboveBase bool
+		for range 100 {
+			d := p.Duration(0)
+			if d < 0 || d >= 50*time.Millisecond {
+				t.Fatalf("got %v, want range [0, %v)", d, 50*time.Millisecond)
+			}
+			sawAboveBase = sawAboveBase || d >= time.Millisecond
+		}
+		if !sawAboveBase {
+			t.Error("got no delay above base, want scaled delays")
+		}
+	})
+}
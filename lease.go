@@ -0,0 +1,70 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrLeaseExpired is returned by [RenewLease] when a lease expires because
+// renewals kept failing.
+var ErrLeaseExpired = errors.New("backoff: lease expired")
+
+// RenewLease keeps a lease or session with the given TTL alive by calling renew
+// until ctx is done, in which case it returns ctx.Err(). The lease is assumed
+// to have just been acquired or renewed when RenewLease is called.
+//
+// After a successful renewal, the next one happens after fraction of the TTL,
+// with ±10% jitter so that a fleet of holders does not renew in lockstep. A
+// fraction outside (0, 1] means 1/3. After a failed renewal, the next one
+// happens after the delay of p for successive failures, but never later than
+// halfway to the expiry of the lease, so renewal is retried faster as expiry
+// approaches. p.MaxAttempts is ignored.
+//
+// Once the lease has expired without a successful renewal, RenewLease returns
+// an error wrapping both [ErrLeaseExpired] and the error of the last renewal.
+func RenewLease(ctx context.Context, p *Policy, ttl time.Duration, fraction float64, renew func(ctx context.Context) error) error {
+	w := p.Waiter
+	if w == nil {
+		tw := &timerWaiter{}
+		defer tw.stop()
+		w = tw
+	}
+	if fraction <= 0 || fraction > 1 {
+		fraction = 1.0 / 3
+	}
+
+	expiry := time.Now().Add(ttl)
+	var lastErr error
+	for failures := 0; ; {
+		var d time.Duration
+		if lastErr == nil {
+			d = jittered(saturatingDuration(float64(ttl)*fraction), 0.1)
+		} else {
+			d = min(p.delay(ctx, failures-1, lastErr), time.Until(expiry)/2)
+		}
+		if d > 0 {
+			if err := w.Wait(ctx, d); err != nil {
+				return err
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		startTime := time.Now()
+		if lastErr = renew(ctx); lastErr == nil {
+			expiry = startTime.Add(ttl)
+			failures = 0
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		failures++
+		if !time.Now().Before(expiry) {
+			return fmt.Errorf("%w: %w", ErrLeaseExpired, lastErr)
+		}
+	}
+}
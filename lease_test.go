@@ -0,0 +1,76 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRenewLease(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	t.Run("RenewsAtFractionOfTTL", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+
+		var w recordingWaiter
+		p := &Policy{Base: time.Second, Cap: time.Minute, Waiter: &w}
+
+		var renewals int
+		err := RenewLease(ctx, p, 30*time.Second, 0, func(context.Context) error {
+			if renewals++; renewals == 5 {
+				cancel()
+			}
+			return nil
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want %v", err, context.Canceled)
+		}
+		if got, want := len(w.delays), 6; got != want {
+			t.Fatalf("got %d waits, want %d", got, want)
+		}
+		for _, d := range w.delays {
+			if lo, hi := 9*time.Second, 11*time.Second; d < lo || d >= hi {
+				t.Errorf("got %v, want range [%v, %v)", d, lo, hi)
+			}
+		}
+	})
+
+	t.Run("RetriesFasterNearExpiry", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+
+		var w recordingWaiter
+		p := &Policy{Base: time.Hour, Cap: time.Hour, Waiter: &w}
+
+		var renewals int
+		err := RenewLease(ctx, p, time.Minute, 0.5, func(context.Context) error {
+			if renewals++; renewals == 3 {
+				cancel()
+			}
+			return errFailed
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want %v", err, context.Canceled)
+		}
+		for _, d := range w.delays[1:] {
+			if d > 30*time.Second {
+				t.Errorf("got %v, want <= %v", d, 30*time.Second)
+			}
+		}
+	})
+
+	t.Run("Expires", func(t *testing.T) {
+		p := &Policy{Base: time.Millisecond, Cap: time.Millisecond}
+		err := RenewLease(context.Background(), p, 20*time.Millisecond, 0.5, func(context.Context) error {
+			return errFailed
+		})
+		if !errors.Is(err, ErrLeaseExpired) {
+			t.Errorf("got %v, want %v", err, ErrLeaseExpired)
+		}
+		if !errors.Is(err, errFailed) {
+			t.Errorf("got %v, want %v", err, errFailed)
+		}
+	})
+}
@@ -0,0 +1,50 @@
+package backoff
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Limits is a precomputed table of per-attempt limits, where the n-th element
+// is min(cap, base*2^n). The table ends at the first element that reaches the
+// cap, which then applies to every later attempt.
+//
+// Looking up a limit in the table is cheaper than recomputing it, so hot loops
+// calling [Limits.Duration] pay one table lookup plus one random draw.
+type Limits []time.Duration
+
+// NewLimits returns the [Limits] for base and cap. It returns nil if base or
+// cap is not positive.
+func NewLimits(base, cap time.Duration) Limits {
+	if base <= 0 || cap <= 0 {
+		return nil
+	}
+
+	l := Limits{min(base, cap)}
+	for limit := l[0]; limit < cap; l = append(l, limit) {
+		if limit > cap>>1 {
+			limit = cap
+		} else {
+			limit <<= 1
+		}
+	}
+	return l
+}
+
+// Limit returns the limit for the attempt. It returns 0 if l is empty or the
+// attempt is negative.
+func (l Limits) Limit(attempt int) time.Duration {
+	if len(l) == 0 || attempt < 0 {
+		return 0
+	}
+	return l[min(attempt, len(l)-1)]
+}
+
+// Duration returns a randomized delay for the attempt. The delay is chosen
+// uniformly from [0, l.Limit(attempt)), like [Duration].
+func (l Limits) Duration(attempt int) time.Duration {
+	if limit := l.Limit(attempt); limit > 1 {
+		return time.Duration(rand.N(int64(limit)))
+	}
+	return 0
+}
@@ -0,0 +1,79 @@
+package backoff
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestNewLimits(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		base time.Duration
+		cap  time.Duration
+		want Limits
+	}{
+		{
+			name: "ZeroBase",
+			base: 0,
+			cap:  time.Second,
+			want: nil,
+		},
+		{
+			name: "ZeroCap",
+			base: time.Second,
+			cap:  0,
+			want: nil,
+		},
+		{
+			name: "CappedByMaximum",
+			base: 100 * time.Millisecond,
+			cap:  300 * time.Millisecond,
+			want: Limits{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond},
+		},
+		{
+			name: "BaseAboveCap",
+			base: time.Second,
+			cap:  time.Millisecond,
+			want: Limits{time.Millisecond},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewLimits(tt.base, tt.cap); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("MaximumCap", func(t *testing.T) {
+		cap := time.Duration(1<<63 - 1)
+		got := NewLimits(time.Nanosecond, cap)
+		if len(got) != 64 || got[63] != cap {
+			t.Errorf("got %d limits ending at %v, want 64 ending at %v", len(got), got[len(got)-1], cap)
+		}
+	})
+}
+
+func TestLimits(t *testing.T) {
+	base := 100 * time.Millisecond
+	cap := 10 * time.Second
+	l := NewLimits(base, cap)
+	for attempt := -1; attempt < 100; attempt++ {
+		var want time.Duration
+		if attempt >= 0 {
+			want = time.Duration(limitNanos(int64(base), int64(cap), attempt))
+		}
+		if got := l.Limit(attempt); got != want {
+			t.Errorf("got %v for attempt %d, want %v", got, attempt, want)
+		}
+		for range 10 {
+			if got := l.Duration(attempt); got < 0 || (want > 0 && got >= want) || (want == 0 && got != 0) {
+				t.Errorf("got %v for attempt %d, want range [0, %v)", got, attempt, want)
+			}
+		}
+	}
+
+	if got := Limits(nil).Duration(0); got != 0 {
+		t.Errorf("got %v, want 0", got)
+	}
+}
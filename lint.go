@@ -0,0 +1,87 @@
+package backoff
+
+import (
+	"fmt"
+	"time"
+)
+
+// Thresholds used by [Policy.Lint].
+const (
+	// lintMinBase is the smallest base below which the first retries
+	// arrive so quickly that they add load without giving the dependency
+	// a chance to recover.
+	lintMinBase = time.Millisecond
+
+	// lintMinTotalWait is the worst-case total wait below which retries
+	// are exhausted before a typical deploy, restart or failover of a
+	// dependency has finished.
+	lintMinTotalWait = 10 * time.Second
+
+	// lintMaxTotalWait is the worst-case total wait above which retries
+	// outlive the common 60s timeouts of load balancers and proxies.
+	lintMaxTotalWait = time.Minute
+)
+
+// Warning is a suspicious aspect of a [Policy] reported by [Policy.Lint].
+type Warning struct {
+	// Code identifies the kind of warning, such as "base-exceeds-cap".
+	Code string
+
+	// Message describes the warning.
+	Message string
+}
+
+// String returns the string representation of w.
+func (w Warning) String() string {
+	return w.Code + ": " + w.Message
+}
+
+// Lint returns warnings about suspicious aspects of p that are not strictly
+// invalid but commonly indicate a misconfiguration. It returns nil if it finds
+// nothing suspicious.
+func (p *Policy) Lint() []Warning {
+	var warnings []Warning
+	warn := func(code, format string, args ...any) {
+		warnings = append(warnings, Warning{Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if p.Base <= 0 || p.Cap <= 0 {
+		warn("invalid", "%v; the policy never waits", p.Validate())
+		return warnings
+	}
+	if p.Base > p.Cap {
+		warn("base-exceeds-cap", "base %v exceeds cap %v; every delay is drawn up to cap and never grows", p.Base, p.Cap)
+	}
+	if p.Base < lintMinBase {
+		warn("tiny-base", "base %v is below %v; early retries will hammer the dependency", p.Base, lintMinBase)
+	}
+	if p.DeadlineReserve < 0 {
+		warn("negative-deadline-reserve", "deadline reserve %v is negative; delays may outlive the context deadline", p.DeadlineReserve)
+	}
+	if p.Jitter == NoJitter {
+		warn("no-jitter", "jitter is disabled; clients that fail together retry together")
+	}
+	if p.MaxAttempts == 1 {
+		warn("no-retries", "max attempts is 1; the policy never retries")
+	}
+
+	if p.MaxAttempts > 1 {
+		n := p.MaxAttempts - 1
+		steps := 0
+		if m := p.multiplier(); validateMultiplier(m) == nil {
+			base, cap := p.scaled()
+			steps = min(n, stepsToCap(int64(base), int64(cap), m))
+		}
+		total := sumDelays(n, steps, func(attempt int) time.Duration {
+			_, hi := p.DelayBounds(attempt)
+			return hi
+		})
+		if total < lintMinTotalWait {
+			warn("short-schedule", "retries are exhausted within %v, before a typical deploy or failover has finished", total)
+		}
+		if total > lintMaxTotalWait && !p.ClampToDeadline {
+			warn("long-schedule", "worst-case total wait exceeds %v, the common timeout of load balancers and proxies; consider ClampToDeadline", lintMaxTotalWait)
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,90 @@
+package backoff
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPolicyLint(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		policy    *Policy
+		wantCodes []string
+	}{
+		{
+			name:   "Reasonable",
+			policy: &Policy{Base: 100 * time.Millisecond, Cap: 10 * time.Second, MaxAttempts: 8},
+		},
+		{
+			name:      "Invalid",
+			policy:    &Policy{Cap: time.Second},
+			wantCodes: []string{"invalid"},
+		},
+		{
+			name:      "BaseExceedsCap",
+			policy:    &Policy{Base: 20 * time.Second, Cap: 10 * time.Second},
+			wantCodes: []string{"base-exceeds-cap"},
+		},
+		{
+			name:      "TinyBase",
+			policy:    &Policy{Base: time.Microsecond, Cap: time.Second},
+			wantCodes: []string{"tiny-base"},
+		},
+		{
+			name:      "NegativeDeadlineReserve",
+			policy:    &Policy{Base: time.Second, Cap: time.Second, DeadlineReserve: -time.Second},
+			wantCodes: []string{"negative-deadline-reserve"},
+		},
+		{
+			name:      "NoRetries",
+			policy:    &Policy{Base: time.Second, Cap: time.Second, MaxAttempts: 1},
+			wantCodes: []string{"no-retries"},
+		},
+		{
+			name:      "ShortSchedule",
+			policy:    &Policy{Base: 10 * time.Millisecond, Cap: time.Second, MaxAttempts: 4},
+			wantCodes: []string{"short-schedule"},
+		},
+		{
+			name:      "LongSchedule",
+			policy:    &Policy{Base: time.Second, Cap: time.Minute, MaxAttempts: 10},
+			wantCodes: []string{"long-schedule"},
+		},
+		{
+			name:      "LongScheduleMaxAttempts",
+			policy:    &Policy{Base: 10 * time.Millisecond, Cap: 20 * time.Millisecond, MaxAttempts: math.MaxInt},
+			wantCodes: []string{"long-schedule"},
+		},
+		{
+			name:      "InvalidMultiplier",
+			policy:    &Policy{Base: time.Second, Cap: time.Minute, MaxAttempts: 10, Multiplier: 0.5},
+			wantCodes: []string{"short-schedule"},
+		},
+		{
+			name:   "LongScheduleClampedToDeadline",
+			policy: &Policy{Base: time.Second, Cap: time.Minute, MaxAttempts: 10, ClampToDeadline: true},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotCodes []string
+			for _, w := range tt.policy.Lint() {
+				gotCodes = append(gotCodes, w.Code)
+				if w.Message == "" {
+					t.Errorf("got empty message for %q", w.Code)
+				}
+			}
+			if !slices.Equal(gotCodes, tt.wantCodes) {
+				t.Errorf("got %v, want %v", gotCodes, tt.wantCodes)
+			}
+		})
+	}
+}
+
+func TestWarningString(t *testing.T) {
+	w := Warning{Code: "foo", Message: "bar"}
+	if got, want := w.String(), "foo: bar"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
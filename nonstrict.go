@@ -0,0 +1,6 @@
+//go:build !backoff_strict
+
+package backoff
+
+// strict reports whether the package is built with the backoff_strict tag.
+const strict = false
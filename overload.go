@@ -0,0 +1,63 @@
+package backoff
+
+import (
+	"sync"
+	"time"
+)
+
+// OverloadSignal reports how overloaded an upstream currently is, such as a
+// value fed from load-shedding headers or ORCA-style load reports. Plug it into
+// [Policy.Overload] to stretch delays while the upstream reports pressure.
+type OverloadSignal interface {
+	// OverloadFactor returns the factor by which the base and cap of a
+	// policy are stretched. A factor of 1 or less means no overload.
+	OverloadFactor() float64
+}
+
+// Pressure is an [OverloadSignal] fed by reports from an upstream. Each report
+// replaces the current factor, which then relaxes linearly back to 1 over the
+// decay period unless a new report arrives.
+//
+// A Pressure is safe for concurrent use. The zero value is ready to use and
+// reports no overload.
+type Pressure struct {
+	// Decay is how long a report takes to relax to no overload. Zero
+	// means 30 seconds.
+	Decay time.Duration
+
+	mu         sync.Mutex
+	factor     float64
+	reportedAt time.Time
+}
+
+var _ OverloadSignal = (*Pressure)(nil)
+
+// Report records that the upstream currently asks for its load to be reduced
+// by factor, such as 2 to roughly halve the retry rate.
+func (p *Pressure) Report(factor float64) {
+	p.mu.Lock()
+	p.factor = factor
+	p.reportedAt = time.Now()
+	p.mu.Unlock()
+}
+
+// OverloadFactor implements [OverloadSignal].
+func (p *Pressure) OverloadFactor() float64 {
+	decay := p.Decay
+	if decay <= 0 {
+		decay = 30 * time.Second
+	}
+
+	p.mu.Lock()
+	factor, reportedAt := p.factor, p.reportedAt
+	p.mu.Unlock()
+	if factor <= 1 {
+		return 1
+	}
+
+	elapsed := time.Since(reportedAt)
+	if elapsed >= decay {
+		return 1
+	}
+	return factor - (factor-1)*float64(elapsed)/float64(decay)
+}
@@ -0,0 +1,61 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPressure(t *testing.T) {
+	t.Run("ZeroValue", func(t *testing.T) {
+		var p Pressure
+		if got := p.OverloadFactor(); got != 1 {
+			t.Errorf("got %v, want 1", got)
+		}
+	})
+
+	t.Run("Relaxes", func(t *testing.T) {
+		p := Pressure{Decay: 50 * time.Millisecond}
+		p.Report(3)
+		if got := p.OverloadFactor(); got <= 2 || got > 3 {
+			t.Errorf("got %v, want range (2, 3]", got)
+		}
+		time.Sleep(60 * time.Millisecond)
+		if got := p.OverloadFactor(); got != 1 {
+			t.Errorf("got %v, want 1", got)
+		}
+	})
+
+	t.Run("LatestReportWins", func(t *testing.T) {
+		var p Pressure
+		p.Report(3)
+		p.Report(0.5)
+		if got := p.OverloadFactor(); got != 1 {
+			t.Errorf("got %v, want 1", got)
+		}
+	})
+}
+
+func TestPolicyOverload(t *testing.T) {
+	var pressure Pressure
+	p := &Policy{Base: 100 * time.Millisecond, Cap: time.Second, Overload: &pressure}
+
+	pressure.Report(1000)
+	var stretched bool
+	for range 100 {
+		if d := p.Duration(0); d >= 100*time.Millisecond {
+			stretched = true
+		} else if d < 0 || d >= 100*time.Second {
+			t.Errorf("got %v, want range [0, %v)", d, 100*time.Second)
+		}
+	}
+	if !stretched {
+		t.Error("got no stretched delays, want some")
+	}
+
+	pressure.Report(1)
+	for range 10 {
+		if d := p.Duration(0); d < 0 || d >= 100*time.Millisecond {
+			t.Errorf("got %v, want range [0, %v)", d, 100*time.Millisecond)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package backoff
+
+import "errors"
+
+// permanentError is an error that must not be retried.
+type permanentError struct {
+	err error
+}
+
+// Error implements [error].
+func (e *permanentError) Error() string { return e.err.Error() }
+
+// Unwrap returns the wrapped error.
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err to mark it as permanent, such as a rejected request that
+// would fail the same way however often it is retried. [Policy.Retry] and the
+// other retrying helpers of this package stop as soon as they see a permanent
+// error and return err itself. Permanent returns nil if err is nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err, or an error it wraps, was marked with
+// [Permanent].
+func IsPermanent(err error) bool {
+	var pe *permanentError
+	return errors.As(err, &pe)
+}
+
+// unwrapPermanent returns the error marked with [Permanent] in err and true,
+// or err and false if there is none.
+func unwrapPermanent(err error) (error, bool) {
+	var pe *permanentError
+	if errors.As(err, &pe) {
+		return pe.err, true
+	}
+	return err, false
+}
@@ -0,0 +1,60 @@
+package backoff
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestPermanent(t *testing.T) {
+	errBadRequest := errors.New("bad request")
+
+	t.Run("Nil", func(t *testing.T) {
+		if err := Permanent(nil); err != nil {
+			t.Errorf("got %v, want nil", err)
+		}
+	})
+
+	for _, tt := range []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "Permanent",
+			err:  Permanent(errBadRequest),
+			want: true,
+		},
+		{
+			name: "Wrapped",
+			err:  fmt.Errorf("call: %w", Permanent(errBadRequest)),
+			want: true,
+		},
+		{
+			name: "Transient",
+			err:  errBadRequest,
+			want: false,
+		},
+		{
+			name: "NilError",
+			err:  nil,
+			want: false,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsPermanent(tt.err); got != tt.want {
+				t.Errorf("got %t, want %t", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("PreservesError", func(t *testing.T) {
+		err := Permanent(errBadRequest)
+		if !errors.Is(err, errBadRequest) {
+			t.Errorf("got %v, want wrapping %v", err, errBadRequest)
+		}
+		if got, want := err.Error(), errBadRequest.Error(); got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	})
+}
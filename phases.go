@@ -0,0 +1,99 @@
+package backoff
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"time"
+)
+
+// Phase is a stage of [Phases].
+type Phase struct {
+	// Policy spaces the attempts made during the phase. Its MaxAttempts,
+	// if positive, ends the phase after that many attempts.
+	Policy *Policy
+
+	// Duration, if positive, ends the phase once that much time has passed
+	// since its first attempt.
+	Duration time.Duration
+}
+
+// Phases is a backoff policy composed of successive phases, such as aggressive
+// retries for the first 30 seconds, then conservative ones up to an hour, then
+// hourly probes:
+//
+//	phases := backoff.Phases{
+//		{Policy: &backoff.Policy{Base: 100 * time.Millisecond, Cap: time.Second}, Duration: 30 * time.Second},
+//		{Policy: &backoff.Policy{Base: time.Second, Cap: time.Minute}, Duration: time.Hour},
+//		{Policy: &backoff.Policy{Base: time.Hour, Cap: time.Hour}},
+//	}
+//
+// A phase ends once either its Duration or the MaxAttempts of its Policy is
+// reached, whichever comes first. Attempts restart from zero in each phase, so
+// every phase begins with its own Base. The last phase never ends on its
+// Duration, and its MaxAttempts ends the whole sequence.
+type Phases []Phase
+
+// Validate reports whether every phase of ps has a valid policy. See
+// [Policy.Validate].
+func (ps Phases) Validate() error {
+	for i, phase := range ps {
+		if err := phase.Policy.Validate(); err != nil {
+			return fmt.Errorf("phase %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Attempts returns an iterator that yields zero-based attempts, counted across
+// all phases, and waits between successive attempts for the delay of the
+// current phase, using the Waiter of its policy. It stops when the last phase
+// runs out of attempts, when ctx is done, or when the consumer breaks.
+func (ps Phases) Attempts(ctx context.Context) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		if len(ps) == 0 {
+			return
+		}
+
+		tw := &timerWaiter{}
+		defer tw.stop()
+
+		phase, phaseStart, n := 0, time.Now(), 0
+		for attempt := 0; ; attempt++ {
+			if ctx.Err() != nil {
+				return
+			}
+			if !yield(attempt) {
+				return
+			}
+
+			n++
+			for phase < len(ps)-1 && ps[phase].ended(n, time.Since(phaseStart)) {
+				phase, phaseStart, n = phase+1, time.Now(), 0
+			}
+
+			p := ps[phase].Policy
+			if phase == len(ps)-1 && p.MaxAttempts > 0 && n >= p.MaxAttempts {
+				return
+			}
+
+			// The first wait of a phase is the one before its first
+			// attempt, which is spaced like the wait after it.
+			if d := p.delay(ctx, max(n-1, 0), nil); d > 0 {
+				var w Waiter = tw
+				if p.Waiter != nil {
+					w = p.Waiter
+				}
+				if w.Wait(ctx, d) != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// ended reports whether the phase has ended after n attempts that took elapsed.
+func (phase Phase) ended(n int, elapsed time.Duration) bool {
+	return (phase.Duration > 0 && elapsed >= phase.Duration) ||
+		(phase.Policy.MaxAttempts > 0 && n >= phase.Policy.MaxAttempts)
+}
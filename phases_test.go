@@ -0,0 +1,113 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPhasesValidate(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		phases  Phases
+		wantErr error
+	}{
+		{
+			name: "Valid",
+			phases: Phases{
+				{Policy: &Policy{Base: time.Millisecond, Cap: time.Second}},
+				{Policy: &Policy{Base: time.Second, Cap: time.Minute}},
+			},
+		},
+		{
+			name: "InvalidSecondPhase",
+			phases: Phases{
+				{Policy: &Policy{Base: time.Millisecond, Cap: time.Second}},
+				{Policy: &Policy{Base: time.Minute, Cap: time.Second}},
+			},
+			wantErr: ErrBaseExceedsCap,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.phases.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPhasesAttempts(t *testing.T) {
+	t.Run("TransitionsOnMaxAttempts", func(t *testing.T) {
+		var fast, slow recordingWaiter
+		phases := Phases{
+			{Policy: &Policy{Base: time.Millisecond, Cap: time.Millisecond, MaxAttempts: 3, Waiter: &fast}},
+			{Policy: &Policy{Base: time.Hour, Cap: time.Hour, MaxAttempts: 2, Waiter: &slow}},
+		}
+
+		var got int
+		for range phases.Attempts(context.Background()) {
+			got++
+		}
+		if want := 5; got != want {
+			t.Errorf("got %d attempts, want %d", got, want)
+		}
+		if got, want := len(fast.delays), 2; got != want {
+			t.Errorf("got %d fast waits, want %d", got, want)
+		}
+		if got, want := len(slow.delays), 2; got != want {
+			t.Errorf("got %d slow waits, want %d", got, want)
+		}
+		for _, d := range slow.delays {
+			if d < 0 || d >= time.Hour {
+				t.Errorf("got %v, want range [0, %v)", d, time.Hour)
+			}
+		}
+	})
+
+	t.Run("TransitionsOnDuration", func(t *testing.T) {
+		var first, last recordingWaiter
+		phases := Phases{
+			{Policy: &Policy{Base: time.Millisecond, Cap: time.Millisecond, Waiter: &first}, Duration: 10 * time.Millisecond},
+			{Policy: &Policy{Base: time.Hour, Cap: time.Hour, MaxAttempts: 1, Waiter: &last}},
+		}
+
+		var got int
+		for range phases.Attempts(context.Background()) {
+			got++
+			if len(last.delays) == 0 {
+				time.Sleep(5 * time.Millisecond)
+			}
+		}
+		if want := 3; got != want {
+			t.Errorf("got %d attempts, want %d", got, want)
+		}
+		if got, want := len(first.delays), 1; got != want {
+			t.Errorf("got %d first-phase waits, want %d", got, want)
+		}
+		if got, want := len(last.delays), 1; got != want {
+			t.Errorf("got %d last-phase waits, want %d", got, want)
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		for range Phases(nil).Attempts(context.Background()) {
+			t.Fatal("got attempt, want none")
+		}
+	})
+
+	t.Run("ContextCanceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		phases := Phases{{Policy: &Policy{Base: time.Second, Cap: time.Second, Waiter: &recordingWaiter{}}}}
+
+		var got int
+		for range phases.Attempts(ctx) {
+			got++
+			cancel()
+		}
+		if want := 1; got != want {
+			t.Errorf("got %d attempts, want %d", got, want)
+		}
+	})
+}
@@ -0,0 +1,640 @@
+package backoff
+
+import (
+	"context"
+	"iter"
+	"log/slog"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+// Policy is a reusable jittered exponential backoff configuration.
+//
+// A Policy must not be modified while it is in use.
+type Policy struct {
+	// Base is the base delay. It must be positive.
+	Base time.Duration
+
+	// Cap is the maximum delay. It must be positive.
+	Cap time.Duration
+
+	// Multiplier is the factor by which the limit of the delays grows per
+	// attempt. It must be at least 1, which keeps the limit at Base. Zero
+	// means 2, doubling the limit per attempt.
+	Multiplier float64
+
+	// MinDelay is the lower bound of every delay, so that retries never
+	// arrive within microseconds of a failure. Zero means no floor. A
+	// MinDelay of Cap or more makes every delay MinDelay.
+	MinDelay time.Duration
+
+	// Jitter is the strategy that randomizes the delays. The default is
+	// [FullJitter].
+	Jitter Jitter
+
+	// WarmUp reports whether the limits of the delays shrink from Cap
+	// toward Base instead of growing from Base toward Cap, taking the same
+	// steps in reverse. It suits polling a dependency that is known to take
+	// a while to come up, so the first checks are not wastefully frequent.
+	WarmUp bool
+
+	// MaxAttempts is the maximum number of attempts. Zero or negative
+	// means no limit.
+	MaxAttempts int
+
+	// MaxElapsedTime is the time budget of [Policy.Attempts]: no attempt
+	// starts more than MaxElapsedTime after the first one started, and
+	// the wait for such an attempt is skipped. The time is measured on the
+	// monotonic clock, so wall-clock jumps do not affect it. Zero or
+	// negative means no limit.
+	MaxElapsedTime time.Duration
+
+	// CoarseClock reports whether the elapsed-time bookkeeping of the
+	// policy, that is, MaxElapsedTime, the Took of a [RetryEvent] and the
+	// stats and ResetAfter of a [Backoff], reads a cached clock refreshed
+	// every millisecond instead of the clock itself. It suits ultra-hot
+	// retry loops where reading the clock on every attempt is measurable
+	// overhead, at the cost of a millisecond of error. The cached clock is
+	// refreshed by a background goroutine that stops once it has not been
+	// read for a second.
+	CoarseClock bool
+
+	// Waiter waits out the delays between attempts. If nil, a runtime
+	// timer is used.
+	Waiter Waiter
+
+	// ClampToDeadline reports whether delays are clamped to the time
+	// remaining until the deadline of the context, less DeadlineReserve,
+	// so that waiting never outlives a deadline the next attempt could
+	// still have met.
+	ClampToDeadline bool
+
+	// StopAtDeadline reports whether [Policy.Attempts] stops, and
+	// [Policy.Sleep] returns [context.DeadlineExceeded] right away, when
+	// the delay would outlive the deadline of the context, less
+	// DeadlineReserve, so that no attempt is burned whose wait leaves it
+	// no time to succeed. It takes precedence over ClampToDeadline there.
+	StopAtDeadline bool
+
+	// DeadlineReserve is the time reserved for the attempt itself when
+	// ClampToDeadline or StopAtDeadline is set.
+	DeadlineReserve time.Duration
+
+	// MinAttemptTimeout is the lower bound of the timeouts returned by
+	// [Policy.AttemptTimeout], so that a deadline split across many
+	// attempts never leaves an attempt too little time to succeed.
+	MinAttemptTimeout time.Duration
+
+	// SubtractAttemptTime reports whether [Policy.Attempts] reduces each
+	// delay by the time the preceding attempt took, never below MinDelay,
+	// so that slow failing attempts do not effectively double the intended
+	// spacing between attempt starts.
+	SubtractAttemptTime bool
+
+	// Record, if not nil, is called with every delay sampled by the
+	// policy, so that a retry timeline can be stored and later reproduced
+	// through Replay.
+	Record func(attempt int, delay time.Duration)
+
+	// Replay, if not nil, replaces random sampling with a recorded
+	// sequence: the delay after attempt n is Replay[n]. Attempts beyond
+	// the end of Replay are sampled as usual.
+	Replay []time.Duration
+
+	// Hint selects how [Policy.DurationHint] combines an externally
+	// supplied delay, such as one carried by [WithDelayHint], with the
+	// sampled one.
+	Hint HintMode
+
+	// Overload, if not nil, stretches Base and Cap by its current factor
+	// while the upstream reports overload.
+	Overload OverloadSignal
+
+	// Slot, if positive, aligns the end of every delay to the next
+	// boundary of a slot of this length, so that a provider processing
+	// work in fixed ticks receives retries batched at tick boundaries
+	// instead of continuously. Boundaries are multiples of Slot since the
+	// zero time.
+	Slot time.Duration
+
+	// SlotJitter, if positive, adds a delay drawn uniformly from
+	// [0, SlotJitter) after the slot boundary, spreading the batched
+	// retries within the slot.
+	SlotJitter time.Duration
+
+	// Latency, if not nil, scales Base by its factor, bounded by Cap, so
+	// that retries against a slow but alive dependency wait longer.
+	Latency *LatencyEWMA
+
+	// Blackout, if not nil, reports whether an attempt at t would fall
+	// inside a blackout window, such as nightly upstream maintenance, and
+	// when that window ends. Such attempts are deferred to a time drawn
+	// uniformly from [end, end+Cap), so clients do not burn attempts
+	// against a service known to be down, nor all return at once when it
+	// comes back. See [DailyBlackout].
+	Blackout func(t time.Time) (end time.Time, ok bool)
+
+	// Observe, if not nil, is called by [Policy.Retry] and the other
+	// retrying helpers of this package after every attempt, before
+	// waiting for the next one. See [AuditLog].
+	Observe func(e RetryEvent)
+
+	// Reauthenticate, if not nil, is called by [Policy.Retry], [Pool],
+	// [Failover] and [Resubscribe] before retrying an attempt whose error
+	// [ClassifyError] classifies as [ClassAuth], so that stale credentials
+	// can be refreshed instead of wasting the remaining attempts. It is
+	// called at most once per retry sequence, or per task of a Pool, after
+	// which the schedule continues as usual. If it fails, its error is
+	// returned.
+	Reauthenticate func(ctx context.Context, err error) error
+
+	// Throttle, if not nil, throttles the attempts of [Policy.Retry],
+	// [Failover] and [Resubscribe] on the client side while the upstream
+	// is throttling them. An attempt it declines is not made, and the
+	// retry gives up with an error wrapping [ErrThrottled] and the error
+	// of the previous attempt, if any.
+	Throttle *AdaptiveThrottle
+
+	// DeadLetter, if not nil, is called with the item, attempt history and
+	// last error of an operation that [Policy.Retry], [Failover],
+	// [Resubscribe], a [Pool] or a [Flusher] gives up on, once its
+	// attempts are exhausted, it fails with an error marked with
+	// [Permanent], Reauthenticate fails or Throttle declines its retry,
+	// for handing it off to a dead-letter queue or alerting. The item is
+	// nil for Policy.Retry, the last endpoint tried for Failover, the last
+	// resume token for Resubscribe, the task for a Pool and the dropped
+	// batch for a Flusher. ctx is the context of the operation, which may
+	// identify it. DeadLetter is not called when the context is done.
+	DeadLetter func(ctx context.Context, e Exhausted[any])
+
+	// IdempotencyKey reports whether [Policy.Retry], [Failover] and
+	// [Resubscribe] generate an idempotency key before the first attempt,
+	// unless the context already carries one, and pass it to every
+	// attempt through the context, so downstream services can deduplicate
+	// retried writes. See [WithIdempotencyKey] and [IdempotencyKey].
+	IdempotencyKey bool
+
+	// Rand, if not nil, is the source of all jitter drawn by the policy,
+	// which makes executions reproducible from a seed, such as one taken
+	// from a fuzz corpus. If nil, the top-level functions of
+	// [math/rand/v2] are used.
+	//
+	// The policy draws from Rand under a package-wide lock, so a Policy
+	// with Rand set is safe for concurrent use, as by the workers of a
+	// [Pool] or the callers of a [Backoff], but Rand must not be used
+	// directly elsewhere at the same time. Concurrent draws interleave in
+	// an unspecified order, so only sequential use is reproducible. Leave
+	// Rand nil for massively parallel retry loops: the top-level functions
+	// draw from per-thread sources that neither contend nor correlate.
+	Rand *rand.Rand
+}
+
+// HintMode selects how an externally supplied delay, such as one from a
+// Retry-After header, a gRPC pushback or a RetryInfo detail, is combined with
+// the delay sampled by a [Policy].
+type HintMode int
+
+// The hint modes.
+const (
+	// HintFloor uses the hint as a lower bound of the sampled delay.
+	HintFloor HintMode = iota
+
+	// HintReplace uses the hint instead of the sampled delay.
+	HintReplace
+
+	// HintJitter draws the delay uniformly from [hint, 2*hint), which
+	// honors the hint while still spreading out clients that received the
+	// same one.
+	HintJitter
+)
+
+// Validate reports whether p is valid. It returns an error wrapping one of
+// [ErrInvalidBase], [ErrInvalidCap], [ErrBaseExceedsCap],
+// [ErrInvalidMultiplier] or [ErrInvalidJitter] for invalid policies, which
+// the other methods of p would otherwise silently treat as "no delay" or
+// "always wait up to cap".
+func (p *Policy) Validate() error {
+	if err := validate(p.Base, p.Cap); err != nil {
+		return err
+	}
+	if p.Multiplier != 0 {
+		if err := validateMultiplier(p.Multiplier); err != nil {
+			return err
+		}
+	}
+	return validateJitter(p.Jitter)
+}
+
+// multiplier returns p.Multiplier, or 2 if it is zero.
+func (p *Policy) multiplier() float64 {
+	if p.Multiplier == 0 {
+		return 2
+	}
+	return p.Multiplier
+}
+
+// Duration returns the randomized delay to wait after the attempt. See
+// [Duration].
+func (p *Policy) Duration(attempt int) time.Duration {
+	return p.duration(p.Rand, attempt)
+}
+
+// duration is like [Policy.Duration] but draws from r, or from the top-level
+// random source if r is nil.
+func (p *Policy) duration(r *rand.Rand, attempt int) time.Duration {
+	var d time.Duration
+	if attempt >= 0 && attempt < len(p.Replay) {
+		d = p.Replay[attempt]
+	} else if lo, hi, ok := p.bounds(attempt); !ok {
+		checkStrict(p.Base, p.Cap, attempt)
+	} else {
+		d = time.Duration(lo + randN(r, hi-lo+1))
+	}
+	if p.Record != nil {
+		p.Record(attempt, d)
+	}
+	return d
+}
+
+// DelayBounds returns the inclusive bounds of the delay [Policy.Duration]
+// draws after the attempt, taking p.Multiplier, p.MinDelay, p.Jitter, p.WarmUp
+// and the current factors of p.Overload and p.Latency into account, but not
+// p.Replay. It returns zeros for invalid parameters.
+func (p *Policy) DelayBounds(attempt int) (lo, hi time.Duration) {
+	l, h, _ := p.bounds(attempt)
+	return time.Duration(l), time.Duration(h)
+}
+
+// bounds implements [Policy.DelayBounds] in nanoseconds. It reports false for
+// invalid parameters.
+func (p *Policy) bounds(attempt int) (lo, hi int64, ok bool) {
+	m := p.multiplier()
+	if p.Base <= 0 || p.Cap <= 0 || attempt < 0 || validateMultiplier(m) != nil || validateJitter(p.Jitter) != nil {
+		return 0, 0, false
+	}
+	base, cap := p.scaled()
+	limit := factorLimitNanos
+	if p.WarmUp {
+		limit = warmUpLimitNanos
+	}
+	lo, hi = jitterBounds(p.Jitter, limit(int64(base), int64(cap), m, attempt))
+	if floor := int64(p.MinDelay); floor > lo {
+		lo, hi = floor, max(hi, floor)
+	}
+	return lo, hi, true
+}
+
+// scaled returns p.Base and p.Cap scaled by the current factors of p.Overload
+// and p.Latency.
+func (p *Policy) scaled() (base, cap time.Duration) {
+	base, cap = p.Base, p.Cap
+	if p.Overload != nil {
+		if f := p.Overload.OverloadFactor(); f > 1 {
+			base = saturatingDuration(float64(base) * f)
+			cap = saturatingDuration(float64(cap) * f)
+		}
+	}
+	if p.Latency != nil {
+		base = min(saturatingDuration(float64(base)*p.Latency.Factor()), cap)
+	}
+	return base, cap
+}
+
+// DurationHint is like [Policy.Duration] but honors an externally supplied
+// delay hint according to p.Hint. A non-positive hint is ignored.
+func (p *Policy) DurationHint(attempt int, hint time.Duration) time.Duration {
+	if hint <= 0 {
+		return p.Duration(attempt)
+	}
+	switch p.Hint {
+	case HintReplace:
+		return hint
+	case HintJitter:
+		if spread := min(hint, math.MaxInt64-hint); spread > 0 {
+			hint += time.Duration(randN(p.Rand, int64(spread)))
+		}
+		return hint
+	default:
+		return max(hint, p.Duration(attempt))
+	}
+}
+
+// Scaled returns a copy of p with Base and Cap multiplied by factor,
+// saturating instead of overflowing. It lets one policy serve traffic of mixed
+// criticality, such as interactive requests backing off less aggressively than
+// batch jobs:
+//
+//	interactive := p.Scaled(0.5)
+//	batch := p.Scaled(4)
+func (p *Policy) Scaled(factor float64) *Policy {
+	scaled := *p
+	scaled.Base = saturatingDuration(float64(p.Base) * factor)
+	scaled.Cap = saturatingDuration(float64(p.Cap) * factor)
+	return &scaled
+}
+
+// Limits returns the precomputed per-attempt limits of p. See [NewLimits]. If
+// p.WarmUp is set, the limits are in reverse order, and the last one, which is
+// p.Base, applies to every later attempt.
+func (p *Policy) Limits() Limits {
+	var l Limits
+	if m := p.multiplier(); m == 2 {
+		l = NewLimits(p.Base, p.Cap)
+	} else if p.Base > 0 && p.Cap > 0 && validateMultiplier(m) == nil {
+		base, cap := int64(p.Base), int64(p.Cap)
+		for steps := range stepsToCap(base, cap, m) + 1 {
+			l = append(l, time.Duration(factorLimitNanos(base, cap, m, steps)))
+		}
+	}
+	if p.WarmUp {
+		slices.Reverse(l)
+	}
+	return l
+}
+
+// Sleep blocks for the delay produced by [Policy.Duration], or until ctx is
+// done, in which case it returns ctx.Err(). The delay is clamped to the
+// deadline of ctx if p.ClampToDeadline is set. If p.StopAtDeadline is set and
+// the delay would outlive the deadline, Sleep returns
+// [context.DeadlineExceeded] without waiting.
+func (p *Policy) Sleep(ctx context.Context, attempt int) error {
+	delay := p.unclampedDelay(attempt)
+	if p.outlivesDeadline(ctx, delay) {
+		return context.DeadlineExceeded
+	}
+	delay = p.clamp(ctx, delay)
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	if p.Waiter != nil {
+		return p.Waiter.Wait(ctx, delay)
+	}
+	return sleep(ctx, delay)
+}
+
+// Attempts returns an iterator that yields zero-based attempts and waits for
+// the delay from [Policy.Duration], clamped to the deadline of ctx if
+// p.ClampToDeadline is set, between successive attempts. It stops after
+// p.MaxAttempts attempts, when the next attempt would exceed p.MaxElapsedTime,
+// when ctx is done, when the consumer breaks, or, if p.StopAtDeadline is set,
+// when the delay would outlive the deadline of ctx.
+func (p *Policy) Attempts(ctx context.Context) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		next := p.next(ctx)
+		attempts(ctx, p.MaxAttempts, p.Waiter, p.monotonic, func(attempt int, took time.Duration) (time.Duration, bool) {
+			return next(attempt, took, nil)
+		})(yield)
+	}
+}
+
+// next returns a function that reports the delay to wait after an attempt
+// that took the given time and failed with err, honoring its [WithDelayHint],
+// or false if no further attempt should be made because of p.MaxAttempts,
+// p.StopAtDeadline or p.MaxElapsedTime. It measures the elapsed time from the
+// start of attempt 0, so a new one is needed for every sequence of attempts.
+func (p *Policy) next(ctx context.Context) func(attempt int, took time.Duration, err error) (time.Duration, bool) {
+	var startTime time.Duration
+	return func(attempt int, took time.Duration, err error) (time.Duration, bool) {
+		if attempt == 0 {
+			startTime = p.monotonic() - took
+		}
+		if p.MaxAttempts > 0 && attempt+1 >= p.MaxAttempts {
+			return 0, false
+		}
+		d := p.unclampedDelayAt(attempt, delayHint(err), time.Now())
+		if p.SubtractAttemptTime {
+			d = max(d-took, p.MinDelay, 0)
+		}
+		if p.outlivesDeadline(ctx, d) {
+			return 0, false
+		}
+		d = p.clamp(ctx, d)
+		if p.MaxElapsedTime > 0 && p.monotonic()-startTime+d > p.MaxElapsedTime {
+			return 0, false
+		}
+		return d, true
+	}
+}
+
+// Schedule draws the delays between p.MaxAttempts attempts up front and
+// returns them as a [Schedule], reusing the storage of dst when it is large
+// enough. If p.MaxAttempts is not positive, the returned schedule is empty.
+func (p *Policy) Schedule(dst Schedule) Schedule {
+	n := max(p.MaxAttempts-1, 0)
+	dst = slices.Grow(dst[:0], n)[:n]
+	if p.Record == nil && p.Replay == nil && p.Overload == nil && p.Latency == nil && p.Rand == nil && !p.WarmUp && p.Jitter == FullJitter && p.multiplier() == 2 && p.MinDelay <= 0 {
+		Fill(dst, p.Base, p.Cap, 0)
+		return dst
+	}
+	for attempt := range dst {
+		dst[attempt] = p.Duration(attempt)
+	}
+	return dst
+}
+
+// Plan returns a sampled schedule of n delays, where the n-th element is the
+// delay after attempt n, without waiting. It is intended for dry-runs,
+// documentation and pre-flight validation of configurations.
+func (p *Policy) Plan(n int) []time.Duration {
+	return p.plan(p.Rand, n)
+}
+
+// PlanSeed is like [Policy.Plan] but draws the delays from a random source
+// seeded with seed instead of p.Rand, so the same seed always yields the same
+// schedule.
+func (p *Policy) PlanSeed(n int, seed uint64) []time.Duration {
+	return p.plan(rand.New(rand.NewPCG(seed, 0)), n)
+}
+
+// plan implements [Policy.Plan] and [Policy.PlanSeed].
+func (p *Policy) plan(r *rand.Rand, n int) []time.Duration {
+	if n <= 0 {
+		return nil
+	}
+	delays := make([]time.Duration, n)
+	for attempt := range delays {
+		delays[attempt] = p.duration(r, attempt)
+	}
+	return delays
+}
+
+// LogValue implements [slog.LogValuer]. It groups the settings of p that shape
+// its schedule, so services logging their effective retry configuration get
+// structured output. The effective multiplier and the jitter are always
+// included, while the other optional settings are only included when set.
+func (p *Policy) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.Duration("base", p.Base),
+		slog.Duration("cap", p.Cap),
+		slog.Int("max_attempts", p.MaxAttempts),
+		slog.Float64("multiplier", p.multiplier()),
+		slog.String("jitter", p.Jitter.String()),
+	}
+	if p.MaxElapsedTime > 0 {
+		attrs = append(attrs, slog.Duration("max_elapsed_time", p.MaxElapsedTime))
+	}
+	if p.MinDelay > 0 {
+		attrs = append(attrs, slog.Duration("min_delay", p.MinDelay))
+	}
+	if p.WarmUp {
+		attrs = append(attrs, slog.Bool("warm_up", true))
+	}
+	if p.ClampToDeadline {
+		attrs = append(attrs, slog.Bool("clamp_to_deadline", true))
+	}
+	if p.StopAtDeadline {
+		attrs = append(attrs, slog.Bool("stop_at_deadline", true))
+	}
+	if p.ClampToDeadline || p.StopAtDeadline {
+		attrs = append(attrs, slog.Duration("deadline_reserve", p.DeadlineReserve))
+	}
+	if p.SubtractAttemptTime {
+		attrs = append(attrs, slog.Bool("subtract_attempt_time", true))
+	}
+	if p.Slot > 0 {
+		attrs = append(attrs, slog.Duration("slot", p.Slot), slog.Duration("slot_jitter", p.SlotJitter))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// delay returns the delay to wait after the attempt failed with err, taking
+// the delay hint carried by err and the options of p that depend on the
+// current time or ctx into account. err may be nil.
+func (p *Policy) delay(ctx context.Context, attempt int, err error) time.Duration {
+	return p.clamp(ctx, p.unclampedDelayAt(attempt, delayHint(err), time.Now()))
+}
+
+// unclampedDelay is like [Policy.delay] but does not clamp the delay to the
+// deadline of ctx.
+func (p *Policy) unclampedDelay(attempt int) time.Duration {
+	return p.unclampedDelayAt(attempt, 0, time.Now())
+}
+
+// unclampedDelayAt is like [Policy.unclampedDelay] but honors the delay hint
+// as [Policy.DurationHint] does and computes the delay as if the current time
+// were now.
+func (p *Policy) unclampedDelayAt(attempt int, hint time.Duration, now time.Time) time.Duration {
+	d := p.DurationHint(attempt, hint)
+	if p.Slot > 0 {
+		at := now.Add(d)
+		if boundary := at.Truncate(p.Slot); boundary.Before(at) {
+			at = boundary.Add(p.Slot)
+		}
+		d = at.Sub(now) + time.Duration(randN(p.Rand, int64(p.SlotJitter)))
+	}
+	if p.Blackout != nil {
+		if end, ok := p.Blackout(now.Add(d)); ok {
+			d = end.Sub(now)
+			if p.Cap > 0 {
+				d += time.Duration(randN(p.Rand, int64(p.Cap)))
+			}
+		}
+	}
+	return d
+}
+
+// clamp clamps d to the time remaining until the deadline of ctx, less
+// p.DeadlineReserve, if p.ClampToDeadline is set.
+func (p *Policy) clamp(ctx context.Context, d time.Duration) time.Duration {
+	if p.ClampToDeadline {
+		if deadline, ok := ctx.Deadline(); ok {
+			d = min(d, max(time.Until(deadline)-p.DeadlineReserve, 0))
+		}
+	}
+	return d
+}
+
+// outlivesDeadline reports whether p.StopAtDeadline is set and waiting for d
+// would outlive the deadline of ctx, less p.DeadlineReserve.
+func (p *Policy) outlivesDeadline(ctx context.Context, d time.Duration) bool {
+	if !p.StopAtDeadline {
+		return false
+	}
+	deadline, ok := ctx.Deadline()
+	return ok && d > time.Until(deadline)-p.DeadlineReserve
+}
+
+// warmUpLimitNanos returns the limit of the delay after the attempt in warm-up
+// mode, that is, the limits from [factorLimitNanos] in reverse order, ending at
+// base.
+func warmUpLimitNanos(base, cap int64, factor float64, attempt int) int64 {
+	steps := stepsToCap(base, cap, factor)
+	if attempt >= steps {
+		return min(base, cap)
+	}
+	return factorLimitNanos(base, cap, factor, steps-attempt)
+}
+
+// stepsToCap returns the first attempt whose limit from [factorLimitNanos]
+// reaches cap, or 0 if the limit never grows because factor is 1.
+func stepsToCap(base, cap int64, factor float64) int {
+	if factor == 1 {
+		return 0
+	}
+	if base >= cap {
+		return 0
+	}
+
+	// Estimate the steps with logarithms, then correct for rounding.
+	steps := max(int(math.Log(float64(cap)/float64(base))/math.Log(factor)), 0)
+	for steps > 0 && factorLimitNanos(base, cap, factor, steps-1) >= cap {
+		steps--
+	}
+	for factorLimitNanos(base, cap, factor, steps) < cap {
+		steps++
+	}
+	return steps
+}
+
+// attempts returns an iterator that yields up to maxAttempts zero-based
+// attempts, or unlimited attempts if maxAttempts is not positive, and uses w to
+// wait for the delay returned by delay between successive attempts. The delay
+// function receives how long the consumer took to process the attempt, as
+// measured by now, or zero if now is nil, and reports false to stop instead.
+// If w is nil, a reusable runtime timer is used.
+func attempts(ctx context.Context, maxAttempts int, w Waiter, now func() time.Duration, delay func(attempt int, took time.Duration) (time.Duration, bool)) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		// The iterator may be ranged over more than once, even
+		// concurrently, so w must not be replaced in place.
+		wait := w
+		if wait == nil {
+			tw := &timerWaiter{}
+			defer tw.stop()
+			wait = tw
+		}
+
+		for attempt := 0; maxAttempts <= 0 || attempt < maxAttempts; attempt++ {
+			if ctx.Err() != nil {
+				return
+			}
+
+			var startTime time.Duration
+			if now != nil {
+				startTime = now()
+			}
+			if !yield(attempt) {
+				return
+			}
+
+			if attempt+1 == maxAttempts {
+				return
+			}
+
+			var took time.Duration
+			if now != nil {
+				took = now() - startTime
+			}
+			d, ok := delay(attempt, took)
+			if !ok {
+				return
+			}
+			if d > 0 {
+				if wait.Wait(ctx, d) != nil {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,719 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPolicyValidate(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		policy  *Policy
+		wantErr error
+	}{
+		{
+			name:   "Valid",
+			policy: &Policy{Base: time.Millisecond, Cap: time.Second},
+		},
+		{
+			name:    "ZeroBase",
+			policy:  &Policy{Cap: time.Second},
+			wantErr: ErrInvalidBase,
+		},
+		{
+			name:    "ZeroCap",
+			policy:  &Policy{Base: time.Millisecond},
+			wantErr: ErrInvalidCap,
+		},
+		{
+			name:    "BaseExceedsCap",
+			policy:  &Policy{Base: time.Second, Cap: time.Millisecond},
+			wantErr: ErrBaseExceedsCap,
+		},
+		{
+			name:    "MultiplierBelowOne",
+			policy:  &Policy{Base: time.Millisecond, Cap: time.Second, Multiplier: 0.5},
+			wantErr: ErrInvalidMultiplier,
+		},
+		{
+			name:    "UnknownJitter",
+			policy:  &Policy{Base: time.Millisecond, Cap: time.Second, Jitter: Jitter(7)},
+			wantErr: ErrInvalidJitter,
+		},
+		{
+			name:    "NaNMultiplier",
+			policy:  &Policy{Base: time.Millisecond, Cap: time.Second, Multiplier: math.NaN()},
+			wantErr: ErrInvalidMultiplier,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.policy.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPolicyDuration(t *testing.T) {
+	p := &Policy{Base: 100 * time.Millisecond, Cap: 300 * time.Millisecond}
+	for range 10 {
+		if got, wantMax := p.Duration(3), 300*time.Millisecond; got < 0 || got >= wantMax {
+			t.Errorf("got %v, want range [0, %v)", got, wantMax)
+		}
+	}
+}
+
+func TestPolicyRecordReplay(t *testing.T) {
+	var recorded []time.Duration
+	p := &Policy{
+		Base:        time.Millisecond,
+		Cap:         time.Second,
+		MaxAttempts: 6,
+		Record: func(attempt int, delay time.Duration) {
+			if attempt != len(recorded) {
+				t.Errorf("got attempt %d, want %d", attempt, len(recorded))
+			}
+			recorded = append(recorded, delay)
+		},
+	}
+	p.Schedule(nil)
+	if len(recorded) != 5 {
+		t.Fatalf("got %d recorded delays, want 5", len(recorded))
+	}
+
+	replay := &Policy{Base: time.Millisecond, Cap: time.Second, MaxAttempts: 6, Replay: recorded}
+	if got := replay.Schedule(nil); !slices.Equal(got, Schedule(recorded)) {
+		t.Errorf("got %v, want %v", got, recorded)
+	}
+	if got, wantMax := replay.Duration(5), 32*time.Millisecond; got < 0 || got >= wantMax {
+		t.Errorf("got %v, want range [0, %v)", got, wantMax)
+	}
+}
+
+func TestPolicyDurationHint(t *testing.T) {
+	base := 100 * time.Millisecond
+	cap := 200 * time.Millisecond
+	hint := time.Second
+	for _, tt := range []struct {
+		name    string
+		mode    HintMode
+		hint    time.Duration
+		wantMin time.Duration
+		wantMax time.Duration
+	}{
+		{
+			name:    "NoHint",
+			mode:    HintReplace,
+			hint:    0,
+			wantMin: 0,
+			wantMax: base,
+		},
+		{
+			name:    "Floor",
+			mode:    HintFloor,
+			hint:    hint,
+			wantMin: hint,
+			wantMax: hint + 1,
+		},
+		{
+			name:    "FloorBelowSample",
+			mode:    HintFloor,
+			hint:    time.Nanosecond,
+			wantMin: time.Nanosecond,
+			wantMax: base,
+		},
+		{
+			name:    "Replace",
+			mode:    HintReplace,
+			hint:    hint,
+			wantMin: hint,
+			wantMax: hint + 1,
+		},
+		{
+			name:    "Jitter",
+			mode:    HintJitter,
+			hint:    hint,
+			wantMin: hint,
+			wantMax: 2 * hint,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Policy{Base: base, Cap: cap, Hint: tt.mode}
+			for range 10 {
+				if got := p.DurationHint(0, tt.hint); got < tt.wantMin || got >= tt.wantMax {
+					t.Errorf("got %v, want range [%v, %v)", got, tt.wantMin, tt.wantMax)
+				}
+			}
+		})
+	}
+
+	t.Run("JitterDoesNotOverflow", func(t *testing.T) {
+		p := &Policy{Hint: HintJitter}
+		if got := p.DurationHint(0, math.MaxInt64); got < 0 {
+			t.Errorf("got %v, want >= 0", got)
+		}
+	})
+}
+
+func TestPolicyPlan(t *testing.T) {
+	p := &Policy{Base: 100 * time.Millisecond, Cap: time.Second}
+
+	t.Run("Envelopes", func(t *testing.T) {
+		got := p.Plan(8)
+		if len(got) != 8 {
+			t.Fatalf("got %d delays, want 8", len(got))
+		}
+		for i, d := range got {
+			if wantMax := p.Limits().Limit(i); d < 0 || d >= wantMax {
+				t.Errorf("got %v at %d, want range [0, %v)", d, i, wantMax)
+			}
+		}
+	})
+
+	t.Run("ZeroN", func(t *testing.T) {
+		if got := p.Plan(0); got != nil {
+			t.Errorf("got %v, want nil", got)
+		}
+	})
+
+	t.Run("Seeded", func(t *testing.T) {
+		got := p.PlanSeed(8, 42)
+		if want := p.PlanSeed(8, 42); !slices.Equal(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+		if other := p.PlanSeed(8, 43); slices.Equal(got, other) {
+			t.Errorf("got same plan %v for different seeds", got)
+		}
+	})
+}
+
+func TestPolicyRand(t *testing.T) {
+	newPolicy := func(seed uint64) *Policy {
+		return &Policy{
+			Base:        time.Millisecond,
+			Cap:         time.Second,
+			MaxAttempts: 8,
+			Hint:        HintJitter,
+			Rand:        rand.New(rand.NewPCG(seed, 0)),
+		}
+	}
+
+	p1, p2 := newPolicy(42), newPolicy(42)
+	if got, want := p1.Schedule(nil), p2.Schedule(nil); !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got, want := p1.Plan(8), p2.Plan(8); !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got, want := p1.DurationHint(0, time.Second), p2.DurationHint(0, time.Second); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	var w1, w2 recordingWaiter
+	p1.Waiter, p2.Waiter = &w1, &w2
+	for range p1.Attempts(context.Background()) {
+	}
+	for range p2.Attempts(context.Background()) {
+	}
+	if !slices.Equal(w1.delays, w2.delays) {
+		t.Errorf("got %v, want %v", w1.delays, w2.delays)
+	}
+}
+
+func TestPolicyRandConcurrent(t *testing.T) {
+	p := &Policy{Base: time.Millisecond, Cap: time.Second, Rand: rand.New(rand.NewPCG(42, 0))}
+	b := &Backoff{Policy: p}
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for attempt := range 100 {
+				if d := p.Duration(attempt % 10); d < 0 || d > p.Cap {
+					t.Errorf("got %v, want range [0, %v]", d, p.Cap)
+				}
+				if d := b.Next(); d < 0 || d > p.Cap {
+					t.Errorf("got %v, want range [0, %v]", d, p.Cap)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestPolicyScaled(t *testing.T) {
+	p := &Policy{Base: 100 * time.Millisecond, Cap: 10 * time.Second, MaxAttempts: 5}
+
+	got := p.Scaled(0.5)
+	if want := (&Policy{Base: 50 * time.Millisecond, Cap: 5 * time.Second, MaxAttempts: 5}); got.Base != want.Base || got.Cap != want.Cap || got.MaxAttempts != want.MaxAttempts {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if p.Base != 100*time.Millisecond || p.Cap != 10*time.Second {
+		t.Errorf("got modified original %+v", p)
+	}
+
+	if got := p.Scaled(math.MaxFloat64); got.Cap != math.MaxInt64 {
+		t.Errorf("got %v, want %v", got.Cap, time.Duration(math.MaxInt64))
+	}
+}
+
+func TestPolicyLimits(t *testing.T) {
+	p := &Policy{Base: 100 * time.Millisecond, Cap: 300 * time.Millisecond}
+	want := Limits{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond}
+	if got := p.Limits(); !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestPolicyMultiplier(t *testing.T) {
+	p := &Policy{Base: 100 * time.Millisecond, Cap: time.Second, Multiplier: 1.5}
+	want := Limits{100 * time.Millisecond, 150 * time.Millisecond, 225 * time.Millisecond, 337500 * time.Microsecond, 506250 * time.Microsecond, 759375 * time.Microsecond, time.Second}
+	if got := p.Limits(); !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for attempt := range 10 {
+		if _, hi := p.DelayBounds(attempt); hi != want.Limit(attempt)-1 {
+			t.Errorf("got %v for attempt %d, want %v", hi, attempt, want.Limit(attempt)-1)
+		}
+	}
+
+	p.WarmUp = true
+	for attempt := range 10 {
+		wantMax := want[max(len(want)-1-attempt, 0)]
+		if _, hi := p.DelayBounds(attempt); hi != wantMax-1 {
+			t.Errorf("got %v for warm-up attempt %d, want %v", hi, attempt, wantMax-1)
+		}
+	}
+
+	p = &Policy{Base: 100 * time.Millisecond, Cap: time.Second, Multiplier: 1}
+	if got, want := p.Limits(), (Limits{100 * time.Millisecond}); !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	p = &Policy{Base: time.Nanosecond, Cap: time.Duration(math.MaxInt64), Multiplier: 1.0000001}
+	if _, hi := p.DelayBounds(math.MaxInt32); hi != math.MaxInt64-1 {
+		t.Errorf("got %v, want %v", hi, time.Duration(math.MaxInt64-1))
+	}
+}
+
+func TestPolicyMinDelay(t *testing.T) {
+	p := &Policy{Base: 100 * time.Millisecond, Cap: time.Second, MinDelay: 150 * time.Millisecond}
+	for _, tt := range []struct {
+		attempt int
+		wantMin time.Duration
+		wantMax time.Duration
+	}{
+		{attempt: 0, wantMin: 150 * time.Millisecond, wantMax: 150 * time.Millisecond},
+		{attempt: 1, wantMin: 150 * time.Millisecond, wantMax: 200*time.Millisecond - 1},
+		{attempt: 4, wantMin: 150 * time.Millisecond, wantMax: time.Second - 1},
+	} {
+		if lo, hi := p.DelayBounds(tt.attempt); lo != tt.wantMin || hi != tt.wantMax {
+			t.Errorf("got bounds [%v, %v] for attempt %d, want [%v, %v]", lo, hi, tt.attempt, tt.wantMin, tt.wantMax)
+		}
+		for range 100 {
+			if got := p.Duration(tt.attempt); got < tt.wantMin || got > tt.wantMax {
+				t.Fatalf("got %v for attempt %d, want range [%v, %v]", got, tt.attempt, tt.wantMin, tt.wantMax)
+			}
+		}
+	}
+
+	p.MaxAttempts = 3
+	for _, d := range p.Schedule(nil) {
+		if d < p.MinDelay {
+			t.Errorf("got %v, want at least %v", d, p.MinDelay)
+		}
+	}
+}
+
+func TestPolicyWarmUp(t *testing.T) {
+	p := &Policy{Base: 100 * time.Millisecond, Cap: time.Second, MaxAttempts: 8, WarmUp: true}
+	want := Limits{time.Second, 800 * time.Millisecond, 400 * time.Millisecond, 200 * time.Millisecond, 100 * time.Millisecond}
+	if got := p.Limits(); !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	for attempt := range 10 {
+		wantMax := want.Limit(attempt)
+		if got := time.Duration(warmUpLimitNanos(int64(p.Base), int64(p.Cap), 2, attempt)); got != wantMax {
+			t.Errorf("got limit %v for attempt %d, want %v", got, attempt, wantMax)
+		}
+		for range 10 {
+			if got := p.Duration(attempt); got < 0 || got >= wantMax {
+				t.Errorf("got %v for attempt %d, want range [0, %v)", got, attempt, wantMax)
+			}
+		}
+	}
+
+	for attempt, d := range p.Schedule(nil) {
+		if wantMax := want.Limit(attempt); d < 0 || d >= wantMax {
+			t.Errorf("got %v for attempt %d, want range [0, %v)", d, attempt, wantMax)
+		}
+	}
+}
+
+func TestPolicyLogValue(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		policy *Policy
+		want   string
+	}{
+		{
+			name:   "Minimal",
+			policy: &Policy{Base: 100 * time.Millisecond, Cap: 10 * time.Second, MaxAttempts: 5},
+			want:   "level=INFO msg=start policy.base=100ms policy.cap=10s policy.max_attempts=5 policy.multiplier=2 policy.jitter=full\n",
+		},
+		{
+			name: "Optional",
+			policy: &Policy{
+				Base:            time.Second,
+				Cap:             time.Minute,
+				MaxElapsedTime:  time.Hour,
+				Multiplier:      1.5,
+				MinDelay:        time.Second,
+				Jitter:          EqualJitter,
+				WarmUp:          true,
+				ClampToDeadline: true,
+				DeadlineReserve: time.Second,
+				Slot:            time.Minute,
+			},
+			want: "level=INFO msg=start policy.base=1s policy.cap=1m0s policy.max_attempts=0 policy.multiplier=1.5 " +
+				"policy.jitter=equal policy.max_elapsed_time=1h0m0s policy.min_delay=1s policy.warm_up=true " +
+				"policy.clamp_to_deadline=true policy.deadline_reserve=1s policy.slot=1m0s policy.slot_jitter=0s\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf strings.Builder
+			logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+				ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+					if a.Key == slog.TimeKey && len(groups) == 0 {
+						return slog.Attr{}
+					}
+					return a
+				},
+			}))
+			logger.Info("start", "policy", tt.policy)
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicySleep(t *testing.T) {
+	t.Run("ZeroDelay", func(t *testing.T) {
+		p := &Policy{}
+		if err := p.Sleep(context.Background(), 0); err != nil {
+			t.Errorf("got %v, want nil", err)
+		}
+	})
+
+	t.Run("ContextCanceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		p := &Policy{Base: time.Hour, Cap: time.Hour}
+		if err := p.Sleep(ctx, 10); !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want %v", err, context.Canceled)
+		}
+	})
+
+	t.Run("ClampToDeadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+		t.Cleanup(cancel)
+
+		var w recordingWaiter
+		p := &Policy{
+			Base:            time.Hour,
+			Cap:             time.Hour,
+			Waiter:          &w,
+			ClampToDeadline: true,
+			DeadlineReserve: 59 * time.Minute,
+		}
+		for range 10 {
+			if err := p.Sleep(ctx, 10); err != nil {
+				t.Fatalf("got %v, want nil", err)
+			}
+		}
+		for _, d := range w.delays {
+			if d > time.Minute {
+				t.Errorf("got %v, want <= %v", d, time.Minute)
+			}
+		}
+	})
+
+	t.Run("ReserveExceedsDeadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		t.Cleanup(cancel)
+
+		p := &Policy{
+			Base:            time.Hour,
+			Cap:             time.Hour,
+			ClampToDeadline: true,
+			DeadlineReserve: time.Hour,
+		}
+		if err := p.Sleep(ctx, 10); err != nil {
+			t.Errorf("got %v, want nil", err)
+		}
+	})
+
+	t.Run("StopAtDeadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+		t.Cleanup(cancel)
+
+		var w recordingWaiter
+		p := &Policy{
+			Base:            time.Minute,
+			Cap:             time.Minute,
+			Waiter:          &w,
+			Jitter:          NoJitter,
+			ClampToDeadline: true,
+			StopAtDeadline:  true,
+		}
+		if err := p.Sleep(ctx, 0); err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+
+		p.DeadlineReserve = 59*time.Minute + 30*time.Second
+		if err := p.Sleep(ctx, 0); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("got %v, want %v", err, context.DeadlineExceeded)
+		}
+		if want := []time.Duration{time.Minute}; !slices.Equal(w.delays, want) {
+			t.Errorf("got %v, want %v", w.delays, want)
+		}
+	})
+
+	t.Run("Slot", func(t *testing.T) {
+		for _, slotJitter := range []time.Duration{0, time.Second} {
+			var w recordingWaiter
+			p := &Policy{
+				Base:       time.Millisecond,
+				Cap:        time.Second,
+				Waiter:     &w,
+				Slot:       time.Minute,
+				SlotJitter: slotJitter,
+			}
+			for range 10 {
+				start := time.Now()
+				if err := p.Sleep(context.Background(), 5); err != nil {
+					t.Fatalf("got %v, want nil", err)
+				}
+				// Allow for the time between start and the sampling.
+				at := start.Add(w.delays[len(w.delays)-1] + 10*time.Millisecond)
+				if offset, hi := at.Sub(at.Truncate(time.Minute)), slotJitter+20*time.Millisecond; offset >= hi {
+					t.Errorf("got offset %v into slot, want range [0, %v)", offset, hi)
+				}
+			}
+		}
+	})
+
+	t.Run("Blackout", func(t *testing.T) {
+		var w recordingWaiter
+		p := &Policy{
+			Base:   time.Millisecond,
+			Cap:    time.Second,
+			Waiter: &w,
+			Blackout: func(time.Time) (time.Time, bool) {
+				return time.Now().Add(time.Hour), true
+			},
+		}
+		for range 10 {
+			if err := p.Sleep(context.Background(), 0); err != nil {
+				t.Fatalf("got %v, want nil", err)
+			}
+		}
+		for _, d := range w.delays {
+			if lo, hi := 59*time.Minute, time.Hour+time.Second; d < lo || d >= hi {
+				t.Errorf("got %v, want range [%v, %v)", d, lo, hi)
+			}
+		}
+	})
+
+	t.Run("CustomWaiter", func(t *testing.T) {
+		var w recordingWaiter
+		p := &Policy{Base: time.Hour, Cap: time.Hour, Waiter: &w}
+		if err := p.Sleep(context.Background(), 10); err != nil {
+			t.Errorf("got %v, want nil", err)
+		}
+		if len(w.delays) != 1 {
+			t.Errorf("got %d waits, want 1", len(w.delays))
+		}
+	})
+}
+
+func TestPolicyAttempts(t *testing.T) {
+	t.Run("IteratesUpToMaxAttempts", func(t *testing.T) {
+		p := &Policy{Base: time.Nanosecond, Cap: time.Nanosecond, MaxAttempts: 3}
+		got := slices.Collect(p.Attempts(context.Background()))
+		if want := []int{0, 1, 2}; !slices.Equal(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("UnlimitedAttempts", func(t *testing.T) {
+		p := &Policy{Base: time.Nanosecond, Cap: time.Nanosecond}
+		var got []int
+		for attempt := range p.Attempts(context.Background()) {
+			got = append(got, attempt)
+			if attempt == 9 {
+				break
+			}
+		}
+		if len(got) != 10 {
+			t.Errorf("got %d attempts, want 10", len(got))
+		}
+	})
+
+	t.Run("MaxElapsedTime", func(t *testing.T) {
+		p := &Policy{
+			Base:           20 * time.Millisecond,
+			Cap:            20 * time.Millisecond,
+			Jitter:         NoJitter,
+			MaxElapsedTime: 50 * time.Millisecond,
+		}
+
+		// Attempts start after about 0ms, 20ms and 40ms. A fourth one would
+		// start after about 60ms, beyond the budget.
+		startTime := time.Now()
+		got := slices.Collect(p.Attempts(context.Background()))
+		if want := []int{0, 1, 2}; !slices.Equal(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+		if elapsed := time.Since(startTime); elapsed > p.MaxElapsedTime {
+			t.Errorf("got %v elapsed, want <= %v", elapsed, p.MaxElapsedTime)
+		}
+	})
+
+	t.Run("StopAtDeadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+		t.Cleanup(cancel)
+
+		var w recordingWaiter
+		p := &Policy{
+			Base:           10 * time.Minute,
+			Cap:            time.Hour,
+			Waiter:         &w,
+			Jitter:         NoJitter,
+			StopAtDeadline: true,
+		}
+
+		// recordingWaiter does not actually wait, so the first delay to
+		// outlive the deadline is the one that reaches the cap of an hour.
+		got := slices.Collect(p.Attempts(ctx))
+		if want := []int{0, 1, 2, 3}; !slices.Equal(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+		if want := []time.Duration{10 * time.Minute, 20 * time.Minute, 40 * time.Minute}; !slices.Equal(w.delays, want) {
+			t.Errorf("got %v, want %v", w.delays, want)
+		}
+	})
+
+	t.Run("UsesWaiter", func(t *testing.T) {
+		var w recordingWaiter
+		p := &Policy{Base: time.Hour, Cap: time.Hour, MaxAttempts: 4, Waiter: &w}
+		for range p.Attempts(context.Background()) {
+		}
+		if len(w.delays) != 3 {
+			t.Errorf("got %d waits, want 3", len(w.delays))
+		}
+	})
+
+	t.Run("SubtractAttemptTime", func(t *testing.T) {
+		var w recordingWaiter
+		p := &Policy{
+			Base:                time.Hour,
+			Cap:                 time.Hour,
+			MaxAttempts:         2,
+			Waiter:              &w,
+			SubtractAttemptTime: true,
+			Replay:              []time.Duration{10 * time.Millisecond},
+		}
+		for range p.Attempts(context.Background()) {
+			time.Sleep(20 * time.Millisecond)
+		}
+		if len(w.delays) != 0 {
+			t.Errorf("got %v, want no waits", w.delays)
+		}
+	})
+
+	t.Run("SubtractAttemptTimeStopsAtMinDelay", func(t *testing.T) {
+		var w recordingWaiter
+		p := &Policy{
+			Base:                50 * time.Millisecond,
+			Cap:                 50 * time.Millisecond,
+			MaxAttempts:         2,
+			Waiter:              &w,
+			MinDelay:            5 * time.Millisecond,
+			Jitter:              NoJitter,
+			SubtractAttemptTime: true,
+		}
+		for range p.Attempts(context.Background()) {
+			time.Sleep(100 * time.Millisecond)
+		}
+		if want := []time.Duration{p.MinDelay}; !slices.Equal(w.delays, want) {
+			t.Errorf("got %v, want %v", w.delays, want)
+		}
+	})
+
+	t.Run("StopsWhenContextCanceledMidWait", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		p := &Policy{Base: time.Hour, Cap: time.Hour, MaxAttempts: 3}
+
+		var got []int
+		for attempt := range p.Attempts(ctx) {
+			got = append(got, attempt)
+			time.AfterFunc(time.Millisecond, cancel)
+		}
+		if want := []int{0}; !slices.Equal(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+}
+
+// recordingWaiter is a [Waiter] that records delays without waiting.
+type recordingWaiter struct {
+	delays []time.Duration
+}
+
+// Wait implements [Waiter].
+func (w *recordingWaiter) Wait(ctx context.Context, d time.Duration) error {
+	w.delays = append(w.delays, d)
+	return ctx.Err()
+}
+
+func TestPolicySchedule(t *testing.T) {
+	t.Run("DrawsMaxAttemptsMinusOneDelays", func(t *testing.T) {
+		p := &Policy{Base: 100 * time.Millisecond, Cap: time.Second, MaxAttempts: 5}
+		got := p.Schedule(nil)
+		if len(got) != 4 {
+			t.Fatalf("got %d delays, want 4", len(got))
+		}
+		for i, d := range got {
+			if wantMax := p.Limits().Limit(i); d < 0 || d >= wantMax {
+				t.Errorf("got %v at %d, want range [0, %v)", d, i, wantMax)
+			}
+		}
+	})
+
+	t.Run("ReusesStorage", func(t *testing.T) {
+		p := &Policy{Base: time.Millisecond, Cap: time.Second, MaxAttempts: 3}
+		dst := make(Schedule, 0, 8)
+		if got := p.Schedule(dst); &got[0] != &dst[:1][0] {
+			t.Error("got new storage, want reused storage")
+		}
+	})
+
+	t.Run("UnlimitedAttempts", func(t *testing.T) {
+		p := &Policy{Base: time.Millisecond, Cap: time.Second}
+		if got := p.Schedule(nil); len(got) != 0 {
+			t.Errorf("got %v, want empty", got)
+		}
+	})
+}
@@ -0,0 +1,174 @@
+package backoff
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Pool processes tasks with a fixed number of workers and requeues failed
+// tasks with per-task backoff, so a failing task is retried after its own
+// delay instead of immediately at the back of the queue, and never holds up
+// other tasks while it waits.
+type Pool[T any] struct {
+	// Policy spaces the attempts of each task as [Policy.Retry] does, so its
+	// MaxAttempts and MaxElapsedTime limit the attempts per task, and its
+	// Reauthenticate is called at most once per task.
+	Policy *Policy
+
+	// Workers is the number of tasks processed concurrently. Zero means
+	// [runtime.GOMAXPROCS].
+	Workers int
+
+	// Process processes a task. A non-nil error requeues it, unless it is
+	// marked with [Permanent].
+	Process func(ctx context.Context, task T) error
+
+	// DeadLetter, if not nil, is called with a task, its attempt history
+	// and its last error once its attempts are exhausted or it fails with
+	// an error marked with [Permanent], which is unwrapped. If
+	// Policy.Reauthenticate fails for a task, the task is given up on with
+	// the error of Reauthenticate. It is not called for tasks dropped
+	// because the context is done. Policy.DeadLetter, if not nil, is
+	// called for the same tasks.
+	DeadLetter func(Exhausted[T])
+}
+
+// poolItem is a task queued in a [Pool].
+type poolItem[T any] struct {
+	task            T
+	history         []AttemptRecord
+	next            func(attempt int, took time.Duration, err error) (time.Duration, bool)
+	reauthenticated bool
+}
+
+// Run processes the tasks received from tasks until tasks is closed and every
+// task has either succeeded or been given up on, in which case it returns nil,
+// or until ctx is done, in which case it returns ctx.Err() and drops the tasks
+// still waiting for a retry.
+func (p *Pool[T]) Run(ctx context.Context, tasks <-chan T) error {
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		work    = make(chan poolItem[T])
+		pending sync.WaitGroup
+		timerMu sync.Mutex
+		timers  = map[*time.Timer]struct{}{}
+	)
+	defer func() {
+		timerMu.Lock()
+		defer timerMu.Unlock()
+		for t := range timers {
+			if t.Stop() {
+				pending.Done()
+			}
+		}
+	}()
+
+	requeue := func(item poolItem[T], d time.Duration) {
+		timerMu.Lock()
+		defer timerMu.Unlock()
+		var t *time.Timer
+		t = time.AfterFunc(d, func() {
+			timerMu.Lock()
+			delete(timers, t)
+			timerMu.Unlock()
+			select {
+			case work <- item:
+			case <-ctx.Done():
+				pending.Done()
+			}
+		})
+		timers[t] = struct{}{}
+	}
+
+	go func() {
+		defer func() {
+			pending.Wait()
+			close(work)
+		}()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case task, ok := <-tasks:
+				if !ok {
+					return
+				}
+				pending.Add(1)
+				select {
+				case work <- poolItem[T]{task: task}:
+				case <-ctx.Done():
+					pending.Done()
+					return
+				}
+			}
+		}
+	}()
+
+	workers := p.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case item, ok := <-work:
+					if !ok {
+						return
+					}
+					start := time.Now()
+					err := p.Process(ctx, item.task)
+					e := RetryEvent{Attempt: len(item.history), Took: time.Since(start)}
+					if e.Err, e.Outcome = settle(ctx, err); e.Outcome == OutcomeRetry {
+						if item.next == nil {
+							item.next = p.Policy.next(ctx)
+						}
+						var ok bool
+						if e.Delay, ok = item.next(e.Attempt, e.Took, err); !ok {
+							e.Outcome = OutcomeExhausted
+						}
+					}
+					if p.Policy.Observe != nil {
+						p.Policy.Observe(e)
+					}
+					lastErr := e.Err
+					if e.Outcome == OutcomeRetry && p.Policy.Reauthenticate != nil && !item.reauthenticated && ClassifyError(err) == ClassAuth {
+						item.reauthenticated = true
+						if reauthErr := p.Policy.Reauthenticate(ctx, err); reauthErr != nil {
+							e.Delay, e.Outcome, lastErr = 0, OutcomePermanent, reauthErr
+						}
+					}
+
+					switch e.Outcome {
+					case OutcomeRetry:
+						item.history = append(item.history, AttemptRecord{Start: start, Took: e.Took, Err: e.Err, Delay: e.Delay})
+						requeue(item, e.Delay)
+					case OutcomePermanent, OutcomeExhausted:
+						history := append(item.history, AttemptRecord{Start: start, Took: e.Took, Err: e.Err})
+						if p.DeadLetter != nil {
+							p.DeadLetter(Exhausted[T]{Item: item.task, History: history, Err: lastErr})
+						}
+						if p.Policy.DeadLetter != nil {
+							p.Policy.DeadLetter(ctx, Exhausted[any]{Item: item.task, History: history, Err: lastErr})
+						}
+						pending.Done()
+					default:
+						pending.Done()
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return parent.Err()
+}
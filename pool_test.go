@@ -0,0 +1,254 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPool(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	t.Run("RetriesAndDeadLetters", func(t *testing.T) {
+		var (
+			mu          sync.Mutex
+			attempts    = map[int]int{}
+			deadLetters []int
+		)
+		p := &Pool[int]{
+			Policy:  &Policy{Base: time.Millisecond, Cap: time.Millisecond, MaxAttempts: 3},
+			Workers: 4,
+			Process: func(_ context.Context, task int) error {
+				mu.Lock()
+				defer mu.Unlock()
+				attempts[task]++
+				switch {
+				case task%3 == 0:
+					return nil
+				case task%3 == 1 && attempts[task] == 2:
+					return nil
+				default:
+					return errFailed
+				}
+			},
+			DeadLetter: func(e Exhausted[int]) {
+				if !errors.Is(e.Err, errFailed) {
+					t.Errorf("got %v, want %v", e.Err, errFailed)
+				}
+				if got, want := e.Attempts(), 3; got != want {
+					t.Errorf("got %d attempts, want %d", got, want)
+				}
+				for i, r := range e.History {
+					if !errors.Is(r.Err, errFailed) {
+						t.Errorf("got %v for attempt %d, want %v", r.Err, i, errFailed)
+					}
+					if got, want := r.Delay == 0, i == len(e.History)-1; got != want {
+						t.Errorf("got zero delay %t for attempt %d, want %t", got, i, want)
+					}
+				}
+				mu.Lock()
+				deadLetters = append(deadLetters, e.Item)
+				mu.Unlock()
+			},
+		}
+
+		tasks := make(chan int)
+		go func() {
+			for task := range 30 {
+				tasks <- task
+			}
+			close(tasks)
+		}()
+		if err := p.Run(context.Background(), tasks); err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+
+		for task := range 30 {
+			want := []int{1, 2, 3}[task%3]
+			if got := attempts[task]; got != want {
+				t.Errorf("got %d attempts for task %d, want %d", got, task, want)
+			}
+		}
+		if len(deadLetters) != 10 {
+			t.Errorf("got %d dead letters, want 10", len(deadLetters))
+		}
+	})
+
+	t.Run("DeadLettersPermanent", func(t *testing.T) {
+		var got []Exhausted[int]
+		p := &Pool[int]{
+			Policy:     &Policy{Base: time.Millisecond, Cap: time.Millisecond, MaxAttempts: 3},
+			Workers:    1,
+			Process:    func(context.Context, int) error { return Permanent(errFailed) },
+			DeadLetter: func(e Exhausted[int]) { got = append(got, e) },
+		}
+		var policyGot []Exhausted[any]
+		p.Policy.DeadLetter = func(_ context.Context, e Exhausted[any]) { policyGot = append(policyGot, e) }
+
+		tasks := make(chan int, 1)
+		tasks <- 1
+		close(tasks)
+		if err := p.Run(context.Background(), tasks); err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		if len(policyGot) != 1 || policyGot[0].Item != 1 || policyGot[0].Err != errFailed {
+			t.Errorf("got %+v, want one dead letter of task 1", policyGot)
+		}
+		if len(got) != 1 {
+			t.Fatalf("got %d dead letters, want 1", len(got))
+		}
+		if got[0].Err != errFailed {
+			t.Errorf("got %v, want %v", got[0].Err, errFailed)
+		}
+		if got[0].Attempts() != 1 {
+			t.Errorf("got %d attempts, want 1", got[0].Attempts())
+		}
+	})
+
+	t.Run("DoesNotBlockOtherTasks", func(t *testing.T) {
+		done := make(chan struct{})
+		p := &Pool[string]{
+			Policy:  &Policy{Base: time.Hour, Cap: time.Hour},
+			Workers: 1,
+			Process: func(_ context.Context, task string) error {
+				if task == "slow" {
+					return errFailed
+				}
+				close(done)
+				return nil
+			},
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		tasks := make(chan string, 2)
+		tasks <- "slow"
+		tasks <- "fast"
+
+		errc := make(chan error, 1)
+		go func() { errc <- p.Run(ctx, tasks) }()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("got timeout, want fast task processed")
+		}
+		cancel()
+		if err := <-errc; !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want %v", err, context.Canceled)
+		}
+	})
+
+	t.Run("HonorsDelayHint", func(t *testing.T) {
+		var delays []time.Duration
+		p := &Pool[int]{
+			Policy: &Policy{
+				Base:        time.Hour,
+				Cap:         time.Hour,
+				MaxAttempts: 2,
+				Hint:        HintReplace,
+				Observe:     func(e RetryEvent) { delays = append(delays, e.Delay) },
+			},
+			Workers: 1,
+			Process: func(context.Context, int) error { return WithDelayHint(errFailed, time.Millisecond) },
+		}
+
+		tasks := make(chan int, 1)
+		tasks <- 1
+		close(tasks)
+		if err := p.Run(context.Background(), tasks); err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		if len(delays) == 0 || delays[0] != time.Millisecond {
+			t.Errorf("got %v, want first delay %v", delays, time.Millisecond)
+		}
+	})
+
+	t.Run("Reauthenticate", func(t *testing.T) {
+		var (
+			reauths  int
+			attempts int
+		)
+		p := &Pool[int]{
+			Policy: &Policy{
+				Base:        time.Millisecond,
+				Cap:         time.Millisecond,
+				MaxAttempts: 3,
+				Reauthenticate: func(context.Context, error) error {
+					reauths++
+					return nil
+				},
+			},
+			Workers: 1,
+			Process: func(context.Context, int) error {
+				attempts++
+				return unauthenticatedError{}
+			},
+		}
+
+		tasks := make(chan int, 1)
+		tasks <- 1
+		close(tasks)
+		if err := p.Run(context.Background(), tasks); err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		if reauths != 1 {
+			t.Errorf("got %d reauthentications, want 1", reauths)
+		}
+		if attempts != 3 {
+			t.Errorf("got %d attempts, want 3", attempts)
+		}
+	})
+
+	t.Run("ReauthenticateFails", func(t *testing.T) {
+		errReauth := errors.New("reauth")
+		var got []Exhausted[int]
+		p := &Pool[int]{
+			Policy: &Policy{
+				Base:           time.Millisecond,
+				Cap:            time.Millisecond,
+				Reauthenticate: func(context.Context, error) error { return errReauth },
+			},
+			Workers:    1,
+			Process:    func(context.Context, int) error { return unauthenticatedError{} },
+			DeadLetter: func(e Exhausted[int]) { got = append(got, e) },
+		}
+
+		tasks := make(chan int, 1)
+		tasks <- 1
+		close(tasks)
+		if err := p.Run(context.Background(), tasks); err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		if len(got) != 1 {
+			t.Fatalf("got %d dead letters, want 1", len(got))
+		}
+		if got[0].Err != errReauth {
+			t.Errorf("got %v, want %v", got[0].Err, errReauth)
+		}
+		if got[0].Attempts() != 1 {
+			t.Errorf("got %d attempts, want 1", got[0].Attempts())
+		}
+	})
+
+	t.Run("MaxElapsedTime", func(t *testing.T) {
+		var got []Exhausted[int]
+		p := &Pool[int]{
+			Policy:     &Policy{Base: time.Hour, Cap: time.Hour, Jitter: NoJitter, MaxElapsedTime: time.Minute},
+			Workers:    1,
+			Process:    func(context.Context, int) error { return errFailed },
+			DeadLetter: func(e Exhausted[int]) { got = append(got, e) },
+		}
+
+		tasks := make(chan int, 1)
+		tasks <- 1
+		close(tasks)
+		if err := p.Run(context.Background(), tasks); err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		if len(got) != 1 {
+			t.Fatalf("got %d dead letters, want 1", len(got))
+		}
+	})
+}
@@ -0,0 +1,60 @@
+package backoff
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Probe checks a dependency with check until ctx is done and calls onChange,
+// if not nil, with the outcome of the first check and whenever the health of
+// the dependency changes afterwards. It returns ctx.Err(), or an error wrapping
+// [ErrInvalidInterval] without calling check if interval is not positive.
+//
+// While the dependency is healthy, checks are spaced by interval with up to
+// ±50% jitter. While it is unhealthy, checks are spaced by the delays of p
+// for successive failed checks, so recovery is detected quickly at first
+// without hammering a dependency that stays down. p.MaxAttempts is ignored.
+func Probe(ctx context.Context, p *Policy, interval time.Duration, check func(ctx context.Context) error, onChange func(healthy bool, err error)) error {
+	if interval <= 0 {
+		return fmt.Errorf("%w: got %v", ErrInvalidInterval, interval)
+	}
+	w := p.Waiter
+	if w == nil {
+		tw := &timerWaiter{}
+		defer tw.stop()
+		w = tw
+	}
+
+	var known, healthy bool
+	for failures := 0; ; {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		err := check(ctx)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !known || healthy != (err == nil) {
+			known, healthy = true, err == nil
+			if onChange != nil {
+				onChange(healthy, err)
+			}
+		}
+
+		var d time.Duration
+		if healthy {
+			failures = 0
+			d = jittered(interval, 0.5)
+		} else {
+			d = p.delay(ctx, failures, err)
+			failures++
+		}
+		if d > 0 {
+			if err := w.Wait(ctx, d); err != nil {
+				return err
+			}
+		}
+	}
+}
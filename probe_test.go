@@ -0,0 +1,73 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestProbe(t *testing.T) {
+	errDown := errors.New("down")
+
+	// The dependency is up, goes down for three checks, and comes back.
+	outcomes := []error{nil, errDown, errDown, errDown, nil, nil}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	var w recordingWaiter
+	p := &Policy{Base: time.Second, Cap: time.Minute, Waiter: &w}
+
+	var checks int
+	var changes []bool
+	err := Probe(ctx, p, time.Hour, func(context.Context) error {
+		err := outcomes[checks]
+		checks++
+		if checks == len(outcomes) {
+			cancel()
+		}
+		return err
+	}, func(healthy bool, err error) {
+		if healthy != (err == nil) {
+			t.Errorf("got healthy %t with error %v", healthy, err)
+		}
+		changes = append(changes, healthy)
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want %v", err, context.Canceled)
+	}
+	if want := []bool{true, false, true}; !slices.Equal(changes, want) {
+		t.Errorf("got %v, want %v", changes, want)
+	}
+
+	if len(w.delays) != 5 {
+		t.Fatalf("got %d waits, want 5", len(w.delays))
+	}
+	for i, d := range w.delays {
+		wantMin, wantMax := time.Duration(0), p.Limits().Limit(i-1)
+		if i == 0 || i == 4 {
+			wantMin, wantMax = 30*time.Minute, 90*time.Minute
+		}
+		if d < wantMin || d >= wantMax {
+			t.Errorf("got %v for wait %d, want range [%v, %v)", d, i, wantMin, wantMax)
+		}
+	}
+}
+
+func TestProbeInvalidInterval(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Second} {
+		var checks int
+		err := Probe(context.Background(), &Policy{}, interval, func(context.Context) error {
+			checks++
+			return nil
+		}, nil)
+		if !errors.Is(err, ErrInvalidInterval) {
+			t.Errorf("got %v, want %v", err, ErrInvalidInterval)
+		}
+		if checks != 0 {
+			t.Errorf("got %d checks, want 0", checks)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package backoff
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// RefreshEarly reports whether a cache entry that expires at expiry and takes
+// delta to recompute should be refreshed now, ahead of its expiry. It
+// implements the probabilistic early expiration of the XFetch algorithm:
+// the closer the entry is to expiring, and the longer it takes to recompute,
+// the more likely a refresh becomes, so that concurrent readers rarely all
+// recompute an expired entry at once. A beta above 1 favors earlier
+// refreshes, and a beta below 1 favors later ones. The default is 1.
+//
+// See "Optimal Probabilistic Cache Stampede Prevention" by Vattani, Chierichetti
+// and Lowenstein.
+func RefreshEarly(expiry time.Time, delta time.Duration, beta float64) bool {
+	now := time.Now()
+	if !now.Before(expiry) {
+		return true
+	}
+	if delta <= 0 || beta <= 0 {
+		return false
+	}
+
+	// 1-rand.Float64() lies in (0, 1], which keeps the logarithm finite.
+	gap := -float64(delta) * beta * math.Log(1-rand.Float64())
+	return gap >= float64(expiry.Sub(now))
+}
+
+// Refresh calls fetch until it succeeds, spacing the calls by the delays of p,
+// and returns the fetched value along with how long the successful call took,
+// which is the delta to pass to [RefreshEarly] for the refreshed entry. It
+// otherwise returns the error [Policy.Retry] would.
+func Refresh[T any](ctx context.Context, p *Policy, fetch func(ctx context.Context) (T, error)) (T, time.Duration, error) {
+	var (
+		v     T
+		delta time.Duration
+	)
+	err := p.Retry(ctx, func(ctx context.Context) error {
+		startTime := time.Now()
+		var err error
+		if v, err = fetch(ctx); err == nil {
+			delta = time.Since(startTime)
+		}
+		return err
+	})
+	if err != nil {
+		var zero T
+		return zero, 0, err
+	}
+	return v, delta, nil
+}
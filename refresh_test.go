@@ -0,0 +1,121 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRefreshEarly(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		expiry time.Duration
+		delta  time.Duration
+		beta   float64
+		want   bool
+	}{
+		{
+			name:   "Expired",
+			expiry: -time.Second,
+			delta:  time.Millisecond,
+			beta:   1,
+			want:   true,
+		},
+		{
+			name:   "FarFromExpiry",
+			expiry: time.Hour,
+			delta:  time.Nanosecond,
+			beta:   1,
+			want:   false,
+		},
+		{
+			name:   "ZeroDelta",
+			expiry: time.Hour,
+			delta:  0,
+			beta:   1,
+			want:   false,
+		},
+		{
+			name:   "ZeroBeta",
+			expiry: time.Hour,
+			delta:  time.Hour,
+			beta:   0,
+			want:   false,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			for range 100 {
+				if got := RefreshEarly(time.Now().Add(tt.expiry), tt.delta, tt.beta); got != tt.want {
+					t.Fatalf("got %t, want %t", got, tt.want)
+				}
+			}
+		})
+	}
+
+	t.Run("Probability", func(t *testing.T) {
+		// With the entry delta*beta*ln(2) from expiry, a refresh happens
+		// with a probability of 1/2.
+		const n = 10000
+		var refreshes int
+		for range n {
+			if RefreshEarly(time.Now().Add(693*time.Millisecond), time.Second, 1) {
+				refreshes++
+			}
+		}
+		if got := float64(refreshes) / n; got < 0.45 || got > 0.55 {
+			t.Errorf("got %v, want range [0.45, 0.55]", got)
+		}
+	})
+}
+
+func TestRefresh(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	t.Run("RetriesUntilSuccess", func(t *testing.T) {
+		p := &Policy{Base: time.Second, Cap: time.Second, MaxAttempts: 5, Waiter: &recordingWaiter{}}
+		var calls int
+		v, delta, err := Refresh(context.Background(), p, func(context.Context) (string, error) {
+			calls++
+			if calls < 3 {
+				return "", errFailed
+			}
+			time.Sleep(time.Millisecond)
+			return "value", nil
+		})
+		if err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		if v != "value" {
+			t.Errorf("got %q, want %q", v, "value")
+		}
+		if delta < time.Millisecond {
+			t.Errorf("got %v, want >= %v", delta, time.Millisecond)
+		}
+		if calls != 3 {
+			t.Errorf("got %d calls, want 3", calls)
+		}
+	})
+
+	t.Run("Exhausted", func(t *testing.T) {
+		p := &Policy{Base: time.Second, Cap: time.Second, MaxAttempts: 2, Waiter: &recordingWaiter{}}
+		_, _, err := Refresh(context.Background(), p, func(context.Context) (int, error) {
+			return 0, errFailed
+		})
+		if !errors.Is(err, errFailed) {
+			t.Errorf("got %v, want %v", err, errFailed)
+		}
+	})
+
+	t.Run("ContextCanceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		p := &Policy{Base: time.Second, Cap: time.Second}
+		_, _, err := Refresh(ctx, p, func(context.Context) (int, error) {
+			return 0, errFailed
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want %v", err, context.Canceled)
+		}
+	})
+}
@@ -0,0 +1,42 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidInterval is returned by [Repeat] and [Probe] when the interval is
+// not positive, which would make them spin.
+var ErrInvalidInterval = errors.New("backoff: interval must be positive")
+
+// Repeat calls fn immediately and then repeatedly until ctx is done, waiting
+// for interval with up to ±jitterFraction jitter between the end of one call
+// and the start of the next, which desynchronizes periodic tasks such as cache
+// refreshes and token renewals across a fleet. It returns ctx.Err(), or an
+// error wrapping [ErrInvalidInterval] without calling fn if interval is not
+// positive.
+//
+// The jitterFraction is clamped to [0, 1]; for example, 0.1 spreads the waits
+// uniformly over [0.9*interval, 1.1*interval).
+func Repeat(ctx context.Context, interval time.Duration, jitterFraction float64, fn func(ctx context.Context)) error {
+	if interval <= 0 {
+		return fmt.Errorf("%w: got %v", ErrInvalidInterval, interval)
+	}
+	var w timerWaiter
+	defer w.stop()
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		fn(ctx)
+
+		if d := jittered(interval, jitterFraction); d > 0 {
+			if err := w.Wait(ctx, d); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRepeat(t *testing.T) {
+	t.Run("RepeatsUntilContextDone", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+
+		var calls int
+		startTime := time.Now()
+		err := Repeat(ctx, 2*time.Millisecond, 0.5, func(context.Context) {
+			calls++
+			if calls == 5 {
+				cancel()
+			}
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want %v", err, context.Canceled)
+		}
+		if calls != 5 {
+			t.Errorf("got %d calls, want 5", calls)
+		}
+		if elapsed, wantMin := time.Since(startTime), 4*time.Millisecond; elapsed < wantMin {
+			t.Errorf("got %v, want >= %v", elapsed, wantMin)
+		}
+	})
+
+	t.Run("ContextCanceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		var calls int
+		if err := Repeat(ctx, time.Hour, 0, func(context.Context) { calls++ }); !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want %v", err, context.Canceled)
+		}
+		if calls != 0 {
+			t.Errorf("got %d calls, want 0", calls)
+		}
+	})
+
+	t.Run("InvalidInterval", func(t *testing.T) {
+		for _, interval := range []time.Duration{0, -time.Second} {
+			var calls int
+			if err := Repeat(context.Background(), interval, 0.1, func(context.Context) { calls++ }); !errors.Is(err, ErrInvalidInterval) {
+				t.Errorf("got %v, want %v", err, ErrInvalidInterval)
+			}
+			if calls != 0 {
+				t.Errorf("got %d calls, want 0", calls)
+			}
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package backoff
+
+import (
+	"context"
+	"time"
+)
+
+// Resubscribe keeps a resumable stream, such as a change stream or a log tail,
+// subscribed. It calls subscribe with the zero resume token first and, every
+// time the stream fails, calls it again with the last token it returned, so
+// consumption resumes where it stopped.
+//
+// subscribe consumes the stream until it ends and returns the last resume
+// token it saw along with the error that ended the stream. Resubscriptions
+// are spaced by the delays of p for successive failures. A subscription that
+// lasted at least healthy before failing resets the failure count, so a
+// stream that breaks once a day does not end up waiting up to p.Cap.
+//
+// Resubscribe returns nil once subscribe returns a nil error, and otherwise
+// the error [Policy.Retry] would, with p.MaxAttempts limiting successive
+// failed subscriptions.
+func Resubscribe[T any](ctx context.Context, p *Policy, healthy time.Duration, subscribe func(ctx context.Context, token T) (T, error)) error {
+	var token T
+	failures := 0
+	return p.retry(ctx, func(ctx context.Context, _ int) error {
+		next, err := subscribe(ctx, token)
+		token = next
+		return err
+	}, func(_ int, took time.Duration, err error) (time.Duration, bool) {
+		if took >= healthy {
+			failures = 0
+		}
+		if p.MaxAttempts > 0 && failures+1 >= p.MaxAttempts {
+			return 0, false
+		}
+		d := p.delay(ctx, failures, err)
+		failures++
+		return d, true
+	}, func() any { return token })
+}
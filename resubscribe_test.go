@@ -0,0 +1,94 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestResubscribe(t *testing.T) {
+	errBroken := errors.New("broken")
+
+	t.Run("ResumesFromLastToken", func(t *testing.T) {
+		var w recordingWaiter
+		p := &Policy{Base: time.Second, Cap: time.Minute, Waiter: &w}
+
+		var tokens []int
+		err := Resubscribe(context.Background(), p, time.Hour, func(_ context.Context, token int) (int, error) {
+			tokens = append(tokens, token)
+			if len(tokens) == 4 {
+				return token + 10, nil
+			}
+			return token + 10, errBroken
+		})
+		if err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		if want := []int{0, 10, 20, 30}; !slices.Equal(tokens, want) {
+			t.Errorf("got %v, want %v", tokens, want)
+		}
+		if got, want := len(w.delays), 3; got != want {
+			t.Fatalf("got %d waits, want %d", got, want)
+		}
+		for i, d := range w.delays {
+			if limit := p.Limits().Limit(i); d < 0 || d >= limit {
+				t.Errorf("got %v, want range [0, %v)", d, limit)
+			}
+		}
+	})
+
+	t.Run("ResetsAfterHealthyPeriod", func(t *testing.T) {
+		var w recordingWaiter
+		p := &Policy{Base: time.Millisecond, Cap: time.Hour, MaxAttempts: 2, Waiter: &w}
+
+		var calls int
+		err := Resubscribe(context.Background(), p, 5*time.Millisecond, func(_ context.Context, token string) (string, error) {
+			calls++
+			if calls <= 3 {
+				time.Sleep(5 * time.Millisecond)
+			}
+			return token, errBroken
+		})
+		if !errors.Is(err, errBroken) {
+			t.Errorf("got %v, want %v", err, errBroken)
+		}
+		if got, want := calls, 4; got != want {
+			t.Errorf("got %d calls, want %d", got, want)
+		}
+		for i, d := range w.delays {
+			if limit := time.Millisecond; d < 0 || d >= limit {
+				t.Errorf("got %v for wait %d, want range [0, %v)", d, i, limit)
+			}
+		}
+	})
+
+	t.Run("HonorsDelayHint", func(t *testing.T) {
+		var w recordingWaiter
+		p := &Policy{Base: time.Hour, Cap: time.Hour, MaxAttempts: 2, Hint: HintReplace, Waiter: &w}
+
+		err := Resubscribe(context.Background(), p, time.Hour, func(_ context.Context, token int) (int, error) {
+			return token, WithDelayHint(errBroken, time.Second)
+		})
+		if !errors.Is(err, errBroken) {
+			t.Errorf("got %v, want %v", err, errBroken)
+		}
+		if want := []time.Duration{time.Second}; !slices.Equal(w.delays, want) {
+			t.Errorf("got %v, want %v", w.delays, want)
+		}
+	})
+
+	t.Run("ContextCanceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		p := &Policy{Base: time.Second, Cap: time.Minute, Waiter: &recordingWaiter{}}
+
+		err := Resubscribe(ctx, p, time.Hour, func(context.Context, struct{}) (struct{}, error) {
+			cancel()
+			return struct{}{}, errBroken
+		})
+		if !errors.Is(err, errBroken) {
+			t.Errorf("got %v, want %v", err, errBroken)
+		}
+	})
+}
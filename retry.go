@@ -0,0 +1,219 @@
+package backoff
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Retry calls fn up to maxAttempts times until it succeeds, waiting for the
+// delay from [Duration] between failed calls. It returns nil once fn succeeds,
+// or the error of the last call once the attempts are exhausted or ctx is
+// done. If fn returns an error marked with [Permanent], Retry stops right away
+// and returns the marked error. If ctx is done before fn is first called, it
+// returns ctx.Err(). If maxAttempts is not positive, fn is not called and
+// Retry returns an error wrapping [ErrInvalidMaxAttempts].
+func Retry(ctx context.Context, maxAttempts int, base, cap time.Duration, fn func(ctx context.Context) error) error {
+	if maxAttempts <= 0 {
+		return fmt.Errorf("%w: got %d", ErrInvalidMaxAttempts, maxAttempts)
+	}
+	p := &Policy{Base: base, Cap: cap, MaxAttempts: maxAttempts}
+	return p.Retry(ctx, fn)
+}
+
+// RetryValue is like [Retry] but for functions that return a value, which it
+// returns once fn succeeds.
+func RetryValue[T any](ctx context.Context, maxAttempts int, base, cap time.Duration, fn func(ctx context.Context) (T, error)) (T, error) {
+	var v T
+	err := Retry(ctx, maxAttempts, base, cap, func(ctx context.Context) error {
+		var err error
+		v, err = fn(ctx)
+		return err
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return v, nil
+}
+
+// Retry is like [Retry] but spaces the calls of fn as [Policy.Attempts] does.
+// If p.MaxAttempts is not positive, fn is called until it succeeds or ctx is
+// done. It reports every attempt to p.Observe, calls p.Reauthenticate and
+// consults p.Throttle, if set. Concurrent callers retrying the same operation can share a single
+// sequence of attempts by wrapping the call in a singleflight group.
+func (p *Policy) Retry(ctx context.Context, fn func(ctx context.Context) error) error {
+	return p.retry(ctx, func(ctx context.Context, _ int) error { return fn(ctx) }, p.next(ctx), nil)
+}
+
+// retry is the loop behind [Policy.Retry] and the other retrying helpers of
+// the package. It calls fn with the zero-based attempt until it succeeds or
+// returns an error marked with [Permanent], and otherwise waits for the delay
+// that next reports for the attempt and its error, giving up when next reports
+// false. It returns the error of the last call of fn, or ctx.Err() if ctx is
+// done before the first one. When it gives up other than because ctx is done,
+// it passes the item returned by item, or nil if item is nil, to
+// p.DeadLetter.
+func (p *Policy) retry(ctx context.Context, fn func(ctx context.Context, attempt int) error, next func(attempt int, took time.Duration, err error) (time.Duration, bool), item func() any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if p.IdempotencyKey {
+		if _, ok := IdempotencyKey(ctx); !ok {
+			ctx = WithIdempotencyKey(ctx, "")
+		}
+	}
+	w := p.Waiter
+	if w == nil {
+		tw := &timerWaiter{}
+		defer tw.stop()
+		w = tw
+	}
+
+	var (
+		lastErr         error
+		history         []AttemptRecord
+		reauthenticated bool
+	)
+	deadLetter := func(err error) error {
+		if p.DeadLetter != nil {
+			var v any
+			if item != nil {
+				v = item()
+			}
+			p.DeadLetter(ctx, Exhausted[any]{Item: v, History: history, Err: err})
+		}
+		return err
+	}
+	for attempt := 0; ; attempt++ {
+		if p.Throttle != nil && !p.Throttle.Allow() {
+			if lastErr == nil {
+				return ErrThrottled
+			}
+			return deadLetter(fmt.Errorf("%w: %w", ErrThrottled, lastErr))
+		}
+
+		var wallStart time.Time
+		if p.DeadLetter != nil {
+			wallStart = time.Now()
+		}
+		startTime := p.monotonic()
+		err := fn(ctx, attempt)
+		if p.Throttle != nil {
+			p.Throttle.Record(err)
+		}
+		lastErr = err
+		e := RetryEvent{Attempt: attempt, Took: p.monotonic() - startTime, Err: err}
+
+		var ok bool
+		if e.Err, e.Outcome = settle(ctx, err); e.Outcome == OutcomeRetry {
+			if e.Delay, ok = next(attempt, e.Took, err); !ok {
+				e.Outcome = OutcomeExhausted
+			}
+		}
+		if p.Observe != nil {
+			p.Observe(e)
+		}
+		if p.DeadLetter != nil && e.Err != nil {
+			history = append(history, AttemptRecord{Start: wallStart, Took: e.Took, Err: e.Err, Delay: e.Delay})
+		}
+		switch e.Outcome {
+		case OutcomeRetry:
+		case OutcomePermanent, OutcomeExhausted:
+			return deadLetter(e.Err)
+		default:
+			return e.Err
+		}
+
+		if p.Reauthenticate != nil && !reauthenticated && ClassifyError(err) == ClassAuth {
+			reauthenticated = true
+			if err := p.Reauthenticate(ctx, err); err != nil {
+				return deadLetter(err)
+			}
+		}
+		if e.Delay > 0 && w.Wait(ctx, e.Delay) != nil {
+			return err
+		}
+	}
+}
+
+// settle returns the outcome of an attempt that returned err, which is
+// [OutcomeRetry] unless the attempt succeeded, err is marked with [Permanent]
+// or ctx is done, along with err without the [Permanent] mark. It does not
+// tell whether the attempts are exhausted.
+func settle(ctx context.Context, err error) (error, Outcome) {
+	if err == nil {
+		return nil, OutcomeSuccess
+	}
+	if permanent, ok := unwrapPermanent(err); ok {
+		return permanent, OutcomePermanent
+	}
+	if ctx.Err() != nil {
+		return err, OutcomeCanceled
+	}
+	return err, OutcomeRetry
+}
+
+// RetryEvent describes an attempt made by [Policy.Retry] or another retrying
+// helper of the package. See [Policy.Observe].
+type RetryEvent struct {
+	// Attempt is the zero-based attempt.
+	Attempt int
+
+	// Took is how long the attempt took.
+	Took time.Duration
+
+	// Err is the error of the attempt, or nil if it succeeded. An error
+	// marked with [Permanent] is unwrapped.
+	Err error
+
+	// Delay is the delay before the next attempt if Outcome is
+	// [OutcomeRetry], and zero otherwise.
+	Delay time.Duration
+
+	// Outcome is what happens after the attempt.
+	Outcome Outcome
+}
+
+// Outcome is what happens after an attempt reported by a [RetryEvent].
+type Outcome int
+
+// The outcomes.
+const (
+	// OutcomeRetry means that the attempt failed and is retried after the
+	// delay.
+	OutcomeRetry Outcome = iota
+
+	// OutcomeSuccess means that the attempt succeeded.
+	OutcomeSuccess
+
+	// OutcomePermanent means that the attempt failed with an error marked
+	// with [Permanent], which is returned.
+	OutcomePermanent
+
+	// OutcomeExhausted means that the attempt failed and the policy allows
+	// no further attempt, so its error is returned.
+	OutcomeExhausted
+
+	// OutcomeCanceled means that the attempt failed and the context is
+	// done, so its error is returned.
+	OutcomeCanceled
+)
+
+// String returns the name of o.
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeRetry:
+		return "retry"
+	case OutcomeSuccess:
+		return "success"
+	case OutcomePermanent:
+		return "permanent"
+	case OutcomeExhausted:
+		return "exhausted"
+	case OutcomeCanceled:
+		return "canceled"
+	}
+	return "Outcome(" + strconv.Itoa(int(o)) + ")"
+}
@@ -0,0 +1,434 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	for _, tt := range []struct {
+		name        string
+		maxAttempts int
+		failures    int
+		wantCalls   int
+		wantErr     error
+	}{
+		{
+			name:        "FirstAttemptSucceeds",
+			maxAttempts: 3,
+			failures:    0,
+			wantCalls:   1,
+		},
+		{
+			name:        "SucceedsAfterFailures",
+			maxAttempts: 3,
+			failures:    2,
+			wantCalls:   3,
+		},
+		{
+			name:        "Exhausted",
+			maxAttempts: 3,
+			failures:    5,
+			wantCalls:   3,
+			wantErr:     errFailed,
+		},
+		{
+			name:        "InvalidMaxAttempts",
+			maxAttempts: 0,
+			wantCalls:   0,
+			wantErr:     ErrInvalidMaxAttempts,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			err := Retry(context.Background(), tt.maxAttempts, time.Nanosecond, time.Microsecond, func(context.Context) error {
+				if calls++; calls <= tt.failures {
+					return errFailed
+				}
+				return nil
+			})
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Errorf("got %v, want %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("got %d calls, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+
+	t.Run("Permanent", func(t *testing.T) {
+		var calls int
+		err := Retry(context.Background(), 5, time.Nanosecond, time.Microsecond, func(context.Context) error {
+			calls++
+			return Permanent(errFailed)
+		})
+		if err != errFailed {
+			t.Errorf("got %v, want %v", err, errFailed)
+		}
+		if calls != 1 {
+			t.Errorf("got %d calls, want 1", calls)
+		}
+	})
+
+	t.Run("ContextCanceledBeforeFirstCall", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := Retry(ctx, 3, time.Second, time.Second, func(context.Context) error {
+			t.Fatal("got call, want none")
+			return nil
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want %v", err, context.Canceled)
+		}
+	})
+
+	t.Run("ContextCanceledWhileWaiting", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var calls int
+		err := Retry(ctx, 10, time.Hour, time.Hour, func(context.Context) error {
+			calls++
+			time.AfterFunc(10*time.Millisecond, cancel)
+			return errFailed
+		})
+		if !errors.Is(err, errFailed) {
+			t.Errorf("got %v, want %v", err, errFailed)
+		}
+		if calls != 1 {
+			t.Errorf("got %d calls, want 1", calls)
+		}
+	})
+}
+
+func TestRetryValue(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	t.Run("SucceedsAfterFailures", func(t *testing.T) {
+		var calls int
+		v, err := RetryValue(context.Background(), 3, time.Nanosecond, time.Microsecond, func(context.Context) (string, error) {
+			if calls++; calls < 3 {
+				return "partial", errFailed
+			}
+			return "value", nil
+		})
+		if err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		if v != "value" {
+			t.Errorf("got %q, want %q", v, "value")
+		}
+	})
+
+	t.Run("Exhausted", func(t *testing.T) {
+		v, err := RetryValue(context.Background(), 2, time.Nanosecond, time.Microsecond, func(context.Context) (int, error) {
+			return 42, errFailed
+		})
+		if !errors.Is(err, errFailed) {
+			t.Errorf("got %v, want %v", err, errFailed)
+		}
+		if v != 0 {
+			t.Errorf("got %d, want 0", v)
+		}
+	})
+
+	t.Run("InvalidMaxAttempts", func(t *testing.T) {
+		_, err := RetryValue(context.Background(), 0, time.Second, time.Second, func(context.Context) (int, error) {
+			t.Fatal("got call, want none")
+			return 0, nil
+		})
+		if !errors.Is(err, ErrInvalidMaxAttempts) {
+			t.Errorf("got %v, want %v", err, ErrInvalidMaxAttempts)
+		}
+	})
+}
+
+func TestPolicyRetry(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	var w recordingWaiter
+	p := &Policy{Base: time.Second, Cap: time.Second, MaxAttempts: 4, Waiter: &w}
+	var calls int
+	err := p.Retry(context.Background(), func(context.Context) error {
+		calls++
+		return errFailed
+	})
+	if !errors.Is(err, errFailed) {
+		t.Errorf("got %v, want %v", err, errFailed)
+	}
+	if calls != 4 {
+		t.Errorf("got %d calls, want 4", calls)
+	}
+	if len(w.delays) != 3 {
+		t.Errorf("got %d waits, want 3", len(w.delays))
+	}
+}
+
+func TestPolicyRetryObserve(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	for _, tt := range []struct {
+		name         string
+		maxAttempts  int
+		errs         []error
+		cancel       bool
+		wantOutcomes []Outcome
+	}{
+		{
+			name:         "Success",
+			maxAttempts:  3,
+			errs:         []error{errFailed, nil},
+			wantOutcomes: []Outcome{OutcomeRetry, OutcomeSuccess},
+		},
+		{
+			name:         "Permanent",
+			maxAttempts:  3,
+			errs:         []error{errFailed, Permanent(errFailed)},
+			wantOutcomes: []Outcome{OutcomeRetry, OutcomePermanent},
+		},
+		{
+			name:         "Exhausted",
+			maxAttempts:  2,
+			errs:         []error{errFailed, errFailed},
+			wantOutcomes: []Outcome{OutcomeRetry, OutcomeExhausted},
+		},
+		{
+			name:         "Canceled",
+			maxAttempts:  3,
+			errs:         []error{errFailed},
+			cancel:       true,
+			wantOutcomes: []Outcome{OutcomeCanceled},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var events []RetryEvent
+			p := &Policy{
+				Base:        time.Second,
+				Cap:         time.Second,
+				MaxAttempts: tt.maxAttempts,
+				Waiter:      &recordingWaiter{},
+				Observe:     func(e RetryEvent) { events = append(events, e) },
+			}
+			p.Retry(ctx, func(context.Context) error {
+				if tt.cancel {
+					cancel()
+				}
+				return tt.errs[len(events)]
+			})
+			if len(events) != len(tt.wantOutcomes) {
+				t.Fatalf("got %d events, want %d", len(events), len(tt.wantOutcomes))
+			}
+			for i, e := range events {
+				if e.Attempt != i {
+					t.Errorf("got attempt %d, want %d", e.Attempt, i)
+				}
+				if e.Outcome != tt.wantOutcomes[i] {
+					t.Errorf("got outcome %v, want %v", e.Outcome, tt.wantOutcomes[i])
+				}
+				if !errors.Is(e.Err, tt.errs[i]) && !IsPermanent(tt.errs[i]) {
+					t.Errorf("got error %v, want %v", e.Err, tt.errs[i])
+				}
+				if e.Outcome == OutcomeRetry && e.Delay <= 0 {
+					t.Errorf("got delay %v, want > 0", e.Delay)
+				}
+				if e.Outcome != OutcomeRetry && e.Delay != 0 {
+					t.Errorf("got delay %v, want 0", e.Delay)
+				}
+			}
+			if last := events[len(events)-1]; last.Outcome == OutcomePermanent && IsPermanent(last.Err) {
+				t.Errorf("got %v, want unwrapped error", last.Err)
+			}
+		})
+	}
+}
+
+func TestPolicyRetryReauthenticate(t *testing.T) {
+	t.Run("OncePerRetry", func(t *testing.T) {
+		var reauthentications, calls int
+		p := &Policy{
+			Base:        time.Second,
+			Cap:         time.Second,
+			MaxAttempts: 4,
+			Waiter:      &recordingWaiter{},
+			Reauthenticate: func(ctx context.Context, err error) error {
+				reauthentications++
+				return nil
+			},
+		}
+		err := p.Retry(context.Background(), func(context.Context) error {
+			calls++
+			return unauthenticatedError{}
+		})
+		if !errors.Is(err, unauthenticatedError{}) {
+			t.Errorf("got %v, want %v", err, unauthenticatedError{})
+		}
+		if calls != 4 {
+			t.Errorf("got %d calls, want 4", calls)
+		}
+		if reauthentications != 1 {
+			t.Errorf("got %d reauthentications, want 1", reauthentications)
+		}
+	})
+
+	t.Run("OtherClass", func(t *testing.T) {
+		var reauthentications int
+		p := &Policy{
+			Base:        time.Second,
+			Cap:         time.Second,
+			MaxAttempts: 2,
+			Waiter:      &recordingWaiter{},
+			Reauthenticate: func(ctx context.Context, err error) error {
+				reauthentications++
+				return nil
+			},
+		}
+		p.Retry(context.Background(), func(context.Context) error { return errors.New("failed") })
+		if reauthentications != 0 {
+			t.Errorf("got %d reauthentications, want 0", reauthentications)
+		}
+	})
+
+	t.Run("Fails", func(t *testing.T) {
+		errReauthenticate := errors.New("reauthenticate")
+		var calls int
+		p := &Policy{
+			Base:        time.Second,
+			Cap:         time.Second,
+			MaxAttempts: 4,
+			Waiter:      &recordingWaiter{},
+			Reauthenticate: func(ctx context.Context, err error) error {
+				return errReauthenticate
+			},
+		}
+		err := p.Retry(context.Background(), func(context.Context) error {
+			calls++
+			return unauthenticatedError{}
+		})
+		if !errors.Is(err, errReauthenticate) {
+			t.Errorf("got %v, want %v", err, errReauthenticate)
+		}
+		if calls != 1 {
+			t.Errorf("got %d calls, want 1", calls)
+		}
+	})
+}
+
+func TestPolicyRetryDeadLetter(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	for _, tt := range []struct {
+		name      string
+		ctx       func() context.Context
+		fn        func(calls int) error
+		wantCalls int
+		wantErr   error
+	}{
+		{
+			name:      "Exhausted",
+			fn:        func(int) error { return errFailed },
+			wantCalls: 3,
+			wantErr:   errFailed,
+		},
+		{
+			name:      "Permanent",
+			fn:        func(calls int) error { return Permanent(errFailed) },
+			wantCalls: 1,
+			wantErr:   errFailed,
+		},
+		{
+			name: "Success",
+			fn:   func(int) error { return nil },
+		},
+		{
+			name: "Canceled",
+			ctx: func() context.Context {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx
+			},
+			fn: func(int) error { return errFailed },
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []Exhausted[any]
+			p := &Policy{
+				Base:        time.Second,
+				Cap:         time.Second,
+				MaxAttempts: 3,
+				Waiter:      &recordingWaiter{},
+				DeadLetter:  func(_ context.Context, e Exhausted[any]) { got = append(got, e) },
+			}
+			ctx := context.Background()
+			if tt.ctx != nil {
+				ctx = tt.ctx()
+			}
+			var calls int
+			p.Retry(ctx, func(context.Context) error {
+				calls++
+				return tt.fn(calls)
+			})
+
+			if tt.wantErr == nil {
+				if len(got) != 0 {
+					t.Errorf("got %d dead letters, want 0", len(got))
+				}
+				return
+			}
+			if len(got) != 1 {
+				t.Fatalf("got %d dead letters, want 1", len(got))
+			}
+			if got[0].Err != tt.wantErr {
+				t.Errorf("got %v, want %v", got[0].Err, tt.wantErr)
+			}
+			if got[0].Item != nil {
+				t.Errorf("got item %v, want nil", got[0].Item)
+			}
+			if got, want := got[0].Attempts(), tt.wantCalls; got != want {
+				t.Errorf("got %d attempts, want %d", got, want)
+			}
+			for i, r := range got[0].History {
+				if got, want := r.Delay == 0, i == len(got[0].History)-1; got != want {
+					t.Errorf("got zero delay %t for attempt %d, want %t", got, i, want)
+				}
+			}
+		})
+	}
+}
+
+func TestOutcomeString(t *testing.T) {
+	for _, tt := range []struct {
+		outcome Outcome
+		want    string
+	}{
+		{OutcomeRetry, "retry"},
+		{OutcomeSuccess, "success"},
+		{OutcomePermanent, "permanent"},
+		{OutcomeExhausted, "exhausted"},
+		{OutcomeCanceled, "canceled"},
+		{Outcome(42), "Outcome(42)"},
+	} {
+		if got := tt.outcome.String(); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestPolicyRetryDelayHint(t *testing.T) {
+	var w recordingWaiter
+	p := &Policy{Base: time.Millisecond, Cap: time.Millisecond, MaxAttempts: 2, Hint: HintReplace, Waiter: &w}
+	errFailed := errors.New("failed")
+	err := p.Retry(context.Background(), func(context.Context) error {
+		return WithDelayHint(errFailed, time.Minute)
+	})
+	if !errors.Is(err, errFailed) {
+		t.Errorf("got %v, want %v", err, errFailed)
+	}
+	if len(w.delays) != 1 || w.delays[0] != time.Minute {
+		t.Errorf("got %v, want [%v]", w.delays, time.Minute)
+	}
+}
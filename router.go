@@ -0,0 +1,118 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+	"time"
+)
+
+// ErrorClass is a class of errors that warrant a particular backoff policy.
+type ErrorClass string
+
+// The error classes reported by [ClassifyError].
+const (
+	// ClassThrottled is the class of errors reporting that the caller is
+	// being rate limited, such as HTTP 429 responses.
+	ClassThrottled ErrorClass = "throttled"
+
+	// ClassConnection is the class of errors reporting a broken or refused
+	// connection.
+	ClassConnection ErrorClass = "connection"
+
+	// ClassTimeout is the class of errors reporting a timeout.
+	ClassTimeout ErrorClass = "timeout"
+
+	// ClassAuth is the class of errors reporting missing or expired
+	// credentials, such as HTTP 401 responses.
+	ClassAuth ErrorClass = "auth"
+
+	// ClassOther is the class of all other errors.
+	ClassOther ErrorClass = "other"
+)
+
+// ClassifyError returns the class of err. An error is [ClassThrottled] if it,
+// or an error it wraps, has a Throttled method that reports true, and
+// [ClassAuth] if it has an Unauthenticated method that reports true. It is
+// [ClassConnection] if it wraps a connection reset, refusal or abort, or an
+// unexpected EOF, and [ClassTimeout] if it wraps
+// [context.DeadlineExceeded] or a [net.Error] that timed out.
+func ClassifyError(err error) ErrorClass {
+	var throttled interface{ Throttled() bool }
+	if errors.As(err, &throttled) && throttled.Throttled() {
+		return ClassThrottled
+	}
+	var unauthenticated interface{ Unauthenticated() bool }
+	if errors.As(err, &unauthenticated) && unauthenticated.Unauthenticated() {
+		return ClassAuth
+	}
+	switch {
+	case errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, syscall.ECONNREFUSED),
+		errors.Is(err, syscall.ECONNABORTED),
+		errors.Is(err, io.ErrUnexpectedEOF):
+		return ClassConnection
+	case errors.Is(err, context.DeadlineExceeded):
+		return ClassTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ClassTimeout
+	}
+	return ClassOther
+}
+
+// Router selects among several policies by the class of the error that failed
+// an attempt, so a single retry call site can wait long after being throttled
+// but retry quickly after a connection reset:
+//
+//	r := &backoff.Router{
+//		Policies: map[backoff.ErrorClass]*backoff.Policy{
+//			backoff.ClassThrottled:  {Base: 5 * time.Second, Cap: 2 * time.Minute},
+//			backoff.ClassConnection: {Base: 10 * time.Millisecond, Cap: time.Second},
+//		},
+//		Default: &backoff.Policy{Base: 100 * time.Millisecond, Cap: 10 * time.Second},
+//	}
+type Router struct {
+	// Classify returns the class of an error. If nil, [ClassifyError] is
+	// used.
+	Classify func(err error) ErrorClass
+
+	// Policies maps error classes to their policies.
+	Policies map[ErrorClass]*Policy
+
+	// Default is the policy for error classes missing from Policies. If
+	// nil, those errors are retried without delay.
+	Default *Policy
+}
+
+// Policy returns the policy for err. It never returns nil: if neither
+// Policies nor Default holds a policy for err, it returns a zero Policy,
+// which retries without delay.
+func (r *Router) Policy(err error) *Policy {
+	classify := r.Classify
+	if classify == nil {
+		classify = ClassifyError
+	}
+	if p := r.Policies[classify(err)]; p != nil {
+		return p
+	}
+	if r.Default != nil {
+		return r.Default
+	}
+	return &Policy{}
+}
+
+// Duration returns the delay to wait after the attempt failed with err, drawn
+// from the policy for err. See [Policy.Duration].
+func (r *Router) Duration(err error, attempt int) time.Duration {
+	return r.Policy(err).Duration(attempt)
+}
+
+// Sleep blocks for the delay after the attempt failed with err, using the
+// policy for err. See [Policy.Sleep].
+func (r *Router) Sleep(ctx context.Context, err error, attempt int) error {
+	return r.Policy(err).Sleep(ctx, attempt)
+}
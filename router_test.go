@@ -0,0 +1,169 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// throttledError is an error reporting throttling.
+type throttledError struct{}
+
+// Error implements [error].
+func (throttledError) Error() string { return "throttled" }
+
+// Throttled reports whether the error reports throttling.
+func (throttledError) Throttled() bool { return true }
+
+// unauthenticatedError is an error reporting missing credentials.
+type unauthenticatedError struct{}
+
+// Error implements [error].
+func (unauthenticatedError) Error() string { return "unauthenticated" }
+
+// Unauthenticated reports whether the error reports missing credentials.
+func (unauthenticatedError) Unauthenticated() bool { return true }
+
+func TestClassifyError(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		err  error
+		want ErrorClass
+	}{
+		{
+			name: "Throttled",
+			err:  fmt.Errorf("request: %w", throttledError{}),
+			want: ClassThrottled,
+		},
+		{
+			name: "Auth",
+			err:  fmt.Errorf("request: %w", unauthenticatedError{}),
+			want: ClassAuth,
+		},
+		{
+			name: "ConnectionReset",
+			err:  &net.OpError{Op: "read", Err: syscall.ECONNRESET},
+			want: ClassConnection,
+		},
+		{
+			name: "ConnectionRefused",
+			err:  fmt.Errorf("dial: %w", syscall.ECONNREFUSED),
+			want: ClassConnection,
+		},
+		{
+			name: "UnexpectedEOF",
+			err:  io.ErrUnexpectedEOF,
+			want: ClassConnection,
+		},
+		{
+			name: "DeadlineExceeded",
+			err:  fmt.Errorf("call: %w", context.DeadlineExceeded),
+			want: ClassTimeout,
+		},
+		{
+			name: "NetTimeout",
+			err:  &net.DNSError{IsTimeout: true},
+			want: ClassTimeout,
+		},
+		{
+			name: "Other",
+			err:  errors.New("boom"),
+			want: ClassOther,
+		},
+		{
+			name: "Nil",
+			err:  nil,
+			want: ClassOther,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyError(tt.err); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouter(t *testing.T) {
+	throttled := &Policy{Base: time.Minute, Cap: time.Minute}
+	connection := &Policy{Base: time.Millisecond, Cap: time.Millisecond}
+	fallback := &Policy{Base: time.Second, Cap: time.Second}
+	r := &Router{
+		Policies: map[ErrorClass]*Policy{
+			ClassThrottled:  throttled,
+			ClassConnection: connection,
+		},
+		Default: fallback,
+	}
+
+	for _, tt := range []struct {
+		name string
+		err  error
+		want *Policy
+	}{
+		{
+			name: "Throttled",
+			err:  throttledError{},
+			want: throttled,
+		},
+		{
+			name: "Connection",
+			err:  syscall.ECONNRESET,
+			want: connection,
+		},
+		{
+			name: "Default",
+			err:  errors.New("boom"),
+			want: fallback,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.Policy(tt.err); got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			if d, limit := r.Duration(tt.err, 0), tt.want.Limits().Limit(0); d < 0 || d >= limit {
+				t.Errorf("got %v, want range [0, %v)", d, limit)
+			}
+		})
+	}
+
+	t.Run("CustomClassify", func(t *testing.T) {
+		r := &Router{
+			Classify: func(error) ErrorClass { return "custom" },
+			Policies: map[ErrorClass]*Policy{"custom": connection},
+			Default:  fallback,
+		}
+		if got := r.Policy(errors.New("boom")); got != connection {
+			t.Errorf("got %+v, want %+v", got, connection)
+		}
+	})
+
+	t.Run("Sleep", func(t *testing.T) {
+		var w recordingWaiter
+		r := &Router{Default: &Policy{Base: time.Hour, Cap: time.Hour, Waiter: &w}}
+		if err := r.Sleep(context.Background(), errors.New("boom"), 0); err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		if len(w.delays) != 1 {
+			t.Errorf("got %d waits, want 1", len(w.delays))
+		}
+	})
+
+	t.Run("NilDefault", func(t *testing.T) {
+		r := &Router{Policies: map[ErrorClass]*Policy{ClassThrottled: throttled}}
+		if got := r.Policy(errors.New("boom")); got == nil {
+			t.Fatal("got nil, want non-nil")
+		}
+		if got := r.Duration(errors.New("boom"), 3); got != 0 {
+			t.Errorf("got %v, want 0", got)
+		}
+		if err := r.Sleep(context.Background(), errors.New("boom"), 3); err != nil {
+			t.Errorf("got %v, want nil", err)
+		}
+	})
+}
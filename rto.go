@@ -0,0 +1,112 @@
+package backoff
+
+import (
+	"sync"
+	"time"
+)
+
+// RTO computes retransmission timeouts as specified by RFC 6298, for
+// request/response protocols over UDP, QUIC datagrams and other transports
+// without built-in retransmission. Callers report the round-trip time of every
+// answered request with [RTO.Observe], report every timeout with
+// [RTO.Backoff], and wait [RTO.Timeout] before retransmitting.
+//
+// Following Karn's algorithm, callers should not observe the round-trip time
+// of a retransmitted request, since its answer cannot be matched to one of the
+// transmissions.
+//
+// An RTO is safe for concurrent use. The zero value is ready to use.
+type RTO struct {
+	// Initial is the timeout before the first round-trip time is
+	// observed. Zero means 1 second.
+	Initial time.Duration
+
+	// Min is the lower bound of the timeout before backoff. Zero means 1
+	// second, as recommended by RFC 6298. Protocols on fast networks
+	// typically use a much lower bound.
+	Min time.Duration
+
+	// Max is the upper bound of the timeout, including backoff. Zero means
+	// 60 seconds.
+	Max time.Duration
+
+	// Granularity is the granularity of the clock measuring round-trip
+	// times, which bounds the variance term from below.
+	Granularity time.Duration
+
+	// Jitter, if positive, spreads the timeouts returned by [RTO.Timeout]
+	// uniformly over ±Jitter of their value, clamped to [0, 1], so that
+	// clients that lost packets at the same time do not retransmit in
+	// lockstep.
+	Jitter float64
+
+	mu       sync.Mutex
+	srtt     time.Duration
+	rttvar   time.Duration
+	sampled  bool
+	backoffs int
+}
+
+// Observe records the round-trip time of an answered request and resets the
+// backoff.
+func (r *RTO) Observe(rtt time.Duration) {
+	rtt = max(rtt, 0)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.sampled {
+		r.srtt, r.rttvar, r.sampled = rtt, rtt/2, true
+	} else {
+		diff := r.srtt - rtt
+		if diff < 0 {
+			diff = -diff
+		}
+		r.rttvar = r.rttvar - r.rttvar/4 + diff/4
+		r.srtt = r.srtt - r.srtt/8 + rtt/8
+	}
+	r.backoffs = 0
+}
+
+// Backoff records that a request timed out, which doubles the timeout up to
+// r.Max until the next call to [RTO.Observe].
+func (r *RTO) Backoff() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.backoffs++
+}
+
+// Timeout returns the current retransmission timeout.
+func (r *RTO) Timeout() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	maxTimeout := r.Max
+	if maxTimeout <= 0 {
+		maxTimeout = time.Minute
+	}
+
+	var rto time.Duration
+	if r.sampled {
+		minTimeout := r.Min
+		if minTimeout <= 0 {
+			minTimeout = time.Second
+		}
+		rto = max(r.srtt+max(r.Granularity, 4*r.rttvar), minTimeout)
+	} else if rto = r.Initial; rto <= 0 {
+		rto = time.Second
+	}
+
+	rto = time.Duration(limitNanos(int64(min(rto, maxTimeout)), int64(maxTimeout), r.backoffs))
+	if r.Jitter > 0 {
+		rto = jittered(rto, r.Jitter)
+	}
+	return rto
+}
+
+// SmoothedRTT returns the smoothed round-trip time and its variation, both
+// zero until a round-trip time has been observed.
+func (r *RTO) SmoothedRTT() (srtt, rttvar time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.srtt, r.rttvar
+}
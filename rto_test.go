@@ -0,0 +1,80 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRTO(t *testing.T) {
+	t.Run("Initial", func(t *testing.T) {
+		var r RTO
+		if got, want := r.Timeout(), time.Second; got != want {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("FirstSample", func(t *testing.T) {
+		r := RTO{Min: time.Millisecond}
+		r.Observe(100 * time.Millisecond)
+		srtt, rttvar := r.SmoothedRTT()
+		if srtt != 100*time.Millisecond || rttvar != 50*time.Millisecond {
+			t.Errorf("got (%v, %v), want (%v, %v)", srtt, rttvar, 100*time.Millisecond, 50*time.Millisecond)
+		}
+		if got, want := r.Timeout(), 300*time.Millisecond; got != want {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("SubsequentSample", func(t *testing.T) {
+		r := RTO{Min: time.Millisecond}
+		r.Observe(100 * time.Millisecond)
+		r.Observe(180 * time.Millisecond)
+		srtt, rttvar := r.SmoothedRTT()
+		if want := 110 * time.Millisecond; srtt != want {
+			t.Errorf("got srtt %v, want %v", srtt, want)
+		}
+		if want := 57500 * time.Microsecond; rttvar != want {
+			t.Errorf("got rttvar %v, want %v", rttvar, want)
+		}
+	})
+
+	t.Run("MinAndGranularity", func(t *testing.T) {
+		r := RTO{Granularity: 10 * time.Millisecond}
+		r.Observe(time.Millisecond)
+		if got, want := r.Timeout(), time.Second; got != want {
+			t.Errorf("got %v, want %v", got, want)
+		}
+
+		r.Min = time.Millisecond
+		for range 20 {
+			r.Observe(time.Millisecond)
+		}
+		if got, lo := r.Timeout(), 11*time.Millisecond; got < lo {
+			t.Errorf("got %v, want >= %v", got, lo)
+		}
+	})
+
+	t.Run("Backoff", func(t *testing.T) {
+		r := RTO{Max: 5 * time.Second}
+		for _, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+			if got := r.Timeout(); got != want {
+				t.Errorf("got %v, want %v", got, want)
+			}
+			r.Backoff()
+		}
+
+		r.Observe(10 * time.Millisecond)
+		if got, want := r.Timeout(), time.Second; got != want {
+			t.Errorf("got %v after observing, want %v", got, want)
+		}
+	})
+
+	t.Run("Jitter", func(t *testing.T) {
+		r := RTO{Jitter: 0.1}
+		for range 100 {
+			if got, lo, hi := r.Timeout(), 900*time.Millisecond, 1100*time.Millisecond; got < lo || got >= hi {
+				t.Errorf("got %v, want range [%v, %v)", got, lo, hi)
+			}
+		}
+	})
+}
@@ -0,0 +1,25 @@
+package backoff
+
+import (
+	"context"
+	"iter"
+	"time"
+)
+
+// Schedule is a precomputed sequence of backoff delays, where the n-th element
+// is the delay to wait after attempt n. A Schedule of length n therefore
+// describes n+1 attempts.
+//
+// A Schedule is typically drawn once with [Policy.Schedule], which makes the
+// complete plan available for logging before it is executed, and then consumed
+// with [Schedule.Attempts] without any further random draws.
+type Schedule []time.Duration
+
+// Attempts returns an iterator that yields len(s)+1 zero-based attempts and
+// waits for s[n] after attempt n. It stops early when ctx is done or when the
+// consumer breaks.
+func (s Schedule) Attempts(ctx context.Context) iter.Seq[int] {
+	return attempts(ctx, len(s)+1, nil, nil, func(attempt int, _ time.Duration) (time.Duration, bool) {
+		return s[attempt], true
+	})
+}
@@ -0,0 +1,52 @@
+package backoff
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSchedule(t *testing.T) {
+	t.Run("Attempts", func(t *testing.T) {
+		s := Schedule{0, time.Nanosecond, 0}
+		got := slices.Collect(s.Attempts(context.Background()))
+		if want := []int{0, 1, 2, 3}; !slices.Equal(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Reusable", func(t *testing.T) {
+		seq := Schedule{time.Nanosecond, time.Nanosecond}.Attempts(context.Background())
+		var wg sync.WaitGroup
+		for range 4 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for range 10 {
+					if got, want := slices.Collect(seq), []int{0, 1, 2}; !slices.Equal(got, want) {
+						t.Errorf("got %v, want %v", got, want)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		got := slices.Collect(Schedule(nil).Attempts(context.Background()))
+		if want := []int{0}; !slices.Equal(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("StopsWhenContextCanceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		got := slices.Collect(Schedule{0}.Attempts(ctx))
+		if got != nil {
+			t.Errorf("got %v, want nil", got)
+		}
+	})
+}
@@ -0,0 +1,133 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrSemaphoreBusy is returned by [Semaphore.Acquire] when the weight cannot
+// be acquired within the attempts of its policy.
+var ErrSemaphoreBusy = errors.New("backoff: semaphore busy")
+
+// ErrInvalidWeight is returned by [Semaphore.Acquire] when the weight is not
+// positive.
+var ErrInvalidWeight = errors.New("backoff: weight must be positive")
+
+// Semaphore is a weighted semaphore whose acquisition backs off with jittered
+// delays on contention instead of queueing waiters in FIFO order, which
+// protects scarce downstream resources from synchronized bursts of waiters
+// that a queue would release all at once.
+//
+// Unlike a queueing semaphore, a Semaphore is not fair: a waiter may lose the
+// race for a released weight to a newer one.
+//
+// A Semaphore is safe for concurrent use. It must not be copied after first
+// use.
+type Semaphore struct {
+	// Size is the total weight available. It must be positive.
+	Size int64
+
+	// Policy spaces the acquisition attempts of a waiter under contention.
+	// Its MaxAttempts limits them.
+	Policy *Policy
+
+	mu    sync.Mutex
+	used  int64
+	stats SemaphoreStats
+}
+
+// SemaphoreStats holds the counters of a [Semaphore].
+type SemaphoreStats struct {
+	// Acquired is the number of successful acquisitions.
+	Acquired int64
+
+	// Contended is the number of successful acquisitions that had to back
+	// off at least once.
+	Contended int64
+
+	// Misses is the number of acquisition attempts that found the
+	// semaphore contended.
+	Misses int64
+
+	// Failed is the number of acquisitions that gave up.
+	Failed int64
+}
+
+// TryAcquire acquires the weight n without waiting and reports whether it
+// succeeded. It reports false if n is not positive.
+func (s *Semaphore) TryAcquire(n int64) bool {
+	return n > 0 && s.tryAcquire(n, false)
+}
+
+// Acquire acquires the weight n, backing off between attempts while the
+// semaphore is contended. It returns [ErrSemaphoreBusy] if the attempts of
+// s.Policy are exhausted or n exceeds s.Size, or ctx.Err() if ctx is done
+// first. It returns an error wrapping [ErrInvalidWeight] if n is not positive.
+func (s *Semaphore) Acquire(ctx context.Context, n int64) error {
+	if n <= 0 {
+		return fmt.Errorf("%w: got %d", ErrInvalidWeight, n)
+	}
+	if n > s.Size {
+		s.fail()
+		return ErrSemaphoreBusy
+	}
+	for attempt := range s.Policy.Attempts(ctx) {
+		if s.tryAcquire(n, attempt > 0) {
+			return nil
+		}
+		s.mu.Lock()
+		s.stats.Misses++
+		s.mu.Unlock()
+	}
+	s.fail()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return ErrSemaphoreBusy
+}
+
+// Release releases the weight n. It panics if n is negative or more weight is
+// released than is held.
+func (s *Semaphore) Release(n int64) {
+	if n < 0 {
+		panic("backoff: semaphore released a negative weight")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n > s.used {
+		panic("backoff: semaphore released more than held")
+	}
+	s.used -= n
+}
+
+// Stats returns a snapshot of the counters of s.
+func (s *Semaphore) Stats() SemaphoreStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// tryAcquire acquires the weight n if it is available. The contended reports
+// whether the caller has backed off before.
+func (s *Semaphore) tryAcquire(n int64, contended bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.used+n > s.Size {
+		return false
+	}
+	s.used += n
+	s.stats.Acquired++
+	if contended {
+		s.stats.Contended++
+	}
+	return true
+}
+
+// fail records an acquisition that gave up.
+func (s *Semaphore) fail() {
+	s.mu.Lock()
+	s.stats.Failed++
+	s.mu.Unlock()
+}
@@ -0,0 +1,131 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSemaphore(t *testing.T) {
+	t.Run("TryAcquire", func(t *testing.T) {
+		s := &Semaphore{Size: 3, Policy: &Policy{Base: time.Millisecond, Cap: time.Millisecond}}
+		if !s.TryAcquire(2) {
+			t.Fatal("got false, want true")
+		}
+		if s.TryAcquire(2) {
+			t.Fatal("got true, want false")
+		}
+		s.Release(2)
+		if !s.TryAcquire(3) {
+			t.Fatal("got false, want true")
+		}
+	})
+
+	t.Run("BacksOffUnderContention", func(t *testing.T) {
+		s := &Semaphore{Size: 2, Policy: &Policy{Base: time.Millisecond, Cap: 5 * time.Millisecond}}
+
+		var (
+			wg      sync.WaitGroup
+			held    atomic.Int64
+			maxHeld atomic.Int64
+		)
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := s.Acquire(context.Background(), 1); err != nil {
+					t.Errorf("got %v, want nil", err)
+					return
+				}
+				h := held.Add(1)
+				for {
+					m := maxHeld.Load()
+					if h <= m || maxHeld.CompareAndSwap(m, h) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				held.Add(-1)
+				s.Release(1)
+			}()
+		}
+		wg.Wait()
+
+		if got := maxHeld.Load(); got > 2 {
+			t.Errorf("got %d concurrent holders, want <= 2", got)
+		}
+		stats := s.Stats()
+		if stats.Acquired != 10 {
+			t.Errorf("got %d acquisitions, want 10", stats.Acquired)
+		}
+		if stats.Contended == 0 || stats.Misses == 0 {
+			t.Errorf("got %+v, want contention", stats)
+		}
+	})
+
+	t.Run("Busy", func(t *testing.T) {
+		s := &Semaphore{Size: 1, Policy: &Policy{Base: time.Millisecond, Cap: time.Millisecond, MaxAttempts: 3, Waiter: &recordingWaiter{}}}
+		s.TryAcquire(1)
+		if err := s.Acquire(context.Background(), 1); !errors.Is(err, ErrSemaphoreBusy) {
+			t.Errorf("got %v, want %v", err, ErrSemaphoreBusy)
+		}
+		if got, want := s.Stats(), (SemaphoreStats{Acquired: 1, Misses: 3, Failed: 1}); got != want {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("WeightExceedsSize", func(t *testing.T) {
+		s := &Semaphore{Size: 1, Policy: &Policy{Base: time.Millisecond, Cap: time.Millisecond}}
+		if err := s.Acquire(context.Background(), 2); !errors.Is(err, ErrSemaphoreBusy) {
+			t.Errorf("got %v, want %v", err, ErrSemaphoreBusy)
+		}
+	})
+
+	t.Run("InvalidWeight", func(t *testing.T) {
+		s := &Semaphore{Size: 2, Policy: &Policy{Base: time.Millisecond, Cap: time.Millisecond}}
+		for _, n := range []int64{0, -1} {
+			if s.TryAcquire(n) {
+				t.Errorf("got true for %d, want false", n)
+			}
+			if err := s.Acquire(context.Background(), n); !errors.Is(err, ErrInvalidWeight) {
+				t.Errorf("got %v for %d, want %v", err, n, ErrInvalidWeight)
+			}
+		}
+		if !s.TryAcquire(2) {
+			t.Error("got false, want true")
+		}
+	})
+
+	t.Run("ContextCanceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		s := &Semaphore{Size: 1, Policy: &Policy{Base: time.Millisecond, Cap: time.Millisecond}}
+		s.TryAcquire(1)
+		if err := s.Acquire(ctx, 1); !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want %v", err, context.Canceled)
+		}
+	})
+
+	t.Run("ReleaseMoreThanHeld", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("got no panic, want panic")
+			}
+		}()
+		(&Semaphore{Size: 1}).Release(1)
+	})
+
+	t.Run("ReleaseNegative", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("got no panic, want panic")
+			}
+		}()
+		(&Semaphore{Size: 1}).Release(-1)
+	})
+}
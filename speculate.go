@@ -0,0 +1,76 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+// Speculate calls fn with k randomly chosen replicas, returns the result of
+// the first call that succeeds and cancels the others, which suits quorum-read
+// style clients that trade extra load for lower tail latency.
+//
+// The calls start one after another, each after a delay drawn from stagger with
+// ±50% jitter, so that a fast replica usually answers before the others are
+// even tried. A failing call starts the next one right away. k is clamped to
+// [1, len(replicas)].
+//
+// Speculate returns the errors of all k calls joined if they all fail, or
+// ctx.Err() if ctx is done first.
+func Speculate[E, T any](ctx context.Context, replicas []E, k int, stagger time.Duration, fn func(ctx context.Context, replica E) (T, error)) (T, error) {
+	var zero T
+	if len(replicas) == 0 {
+		return zero, ErrNoEndpoints
+	}
+	k = min(max(k, 1), len(replicas))
+	order := rand.Perm(len(replicas))[:k]
+
+	callCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		v   T
+		err error
+	}
+	results := make(chan result, k)
+
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	var launched int
+	launch := func() {
+		replica := replicas[order[launched]]
+		launched++
+		go func() {
+			v, err := fn(callCtx, replica)
+			results <- result{v, err}
+		}()
+		if launched < k {
+			timer.Reset(jittered(stagger, 0.5))
+		} else {
+			timer.Stop()
+		}
+	}
+	launch()
+
+	errs := make([]error, 0, k)
+	for {
+		select {
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		case <-timer.C:
+			launch()
+		case r := <-results:
+			if r.err == nil {
+				return r.v, nil
+			}
+			if errs = append(errs, r.err); len(errs) == k {
+				return zero, errors.Join(errs...)
+			}
+			if launched < k {
+				launch()
+			}
+		}
+	}
+}
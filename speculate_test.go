@@ -0,0 +1,80 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSpeculate(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	t.Run("FirstSuccessWins", func(t *testing.T) {
+		replicas := []time.Duration{time.Hour, time.Hour, 0}
+		got, err := Speculate(context.Background(), replicas, 3, time.Millisecond, func(ctx context.Context, latency time.Duration) (time.Duration, error) {
+			select {
+			case <-time.After(latency):
+				return latency, nil
+			case <-ctx.Done():
+				return 0, ctx.Err()
+			}
+		})
+		if err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		if got != 0 {
+			t.Errorf("got %v, want 0", got)
+		}
+	})
+
+	t.Run("StaggersStarts", func(t *testing.T) {
+		var calls atomic.Int32
+		_, err := Speculate(context.Background(), []int{1, 2, 3}, 3, time.Hour, func(context.Context, int) (int, error) {
+			calls.Add(1)
+			return 0, nil
+		})
+		if err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		if got := calls.Load(); got != 1 {
+			t.Errorf("got %d calls, want 1", got)
+		}
+	})
+
+	t.Run("FailureStartsNextImmediately", func(t *testing.T) {
+		var calls atomic.Int32
+		_, err := Speculate(context.Background(), []int{1, 2, 3, 4}, 2, time.Hour, func(context.Context, int) (int, error) {
+			calls.Add(1)
+			return 0, errFailed
+		})
+		if !errors.Is(err, errFailed) {
+			t.Errorf("got %v, want %v", err, errFailed)
+		}
+		if got := calls.Load(); got != 2 {
+			t.Errorf("got %d calls, want 2", got)
+		}
+	})
+
+	t.Run("NoReplicas", func(t *testing.T) {
+		_, err := Speculate(context.Background(), []int(nil), 1, time.Millisecond, func(context.Context, int) (int, error) {
+			return 0, nil
+		})
+		if !errors.Is(err, ErrNoEndpoints) {
+			t.Errorf("got %v, want %v", err, ErrNoEndpoints)
+		}
+	})
+
+	t.Run("ContextCanceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+		_, err := Speculate(ctx, []int{1}, 1, time.Millisecond, func(ctx context.Context, _ int) (int, error) {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want %v", err, context.Canceled)
+		}
+	})
+}
@@ -0,0 +1,194 @@
+package backoff
+
+import (
+	"context"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// Backoff is a stateful backoff for event-driven code, such as a reconnect
+// handler, that does not fit an iterator. It tracks its own attempt counter:
+// [Backoff.Next] returns the delay after the current attempt and advances the
+// counter, and [Backoff.Reset] starts over once the connection is healthy
+// again. Alternatively, with ResetAfter set, report successes with
+// [Backoff.Success] and let the counter reset itself once a success has
+// lasted long enough.
+//
+// A Backoff is safe for concurrent use and lock-free: the attempt counter and
+// the number of resets share one atomically updated word, so hot shared state
+// does not serialize goroutines. It must not be copied after first use.
+type Backoff struct {
+	// Policy computes the delays. It must not be nil. Its MaxAttempts is
+	// not enforced; compare it with [Backoff.Attempt] to give up.
+	Policy *Policy
+
+	// Decorrelated reports whether delays are drawn with Decorrelated
+	// Jitter from the previous delay, like [DurationDecorrelated], instead
+	// of from the limit of the attempt. Only the Base, Cap and Rand of
+	// Policy are used then.
+	Decorrelated bool
+
+	// ResetAfter, if positive, is how long a success reported by
+	// [Backoff.Success] must have lasted when [Backoff.Next] is next
+	// called for the attempt counter to be reset first, so that a
+	// connection that stayed up for a while starts over from Base while
+	// one that flaps keeps backing off. Like the elapsed time in
+	// [BackoffStats], it is measured on the monotonic clock.
+	ResetAfter time.Duration
+
+	// state packs the attempt counter into its low 32 bits and the number
+	// of resets into its high 32 bits.
+	state atomic.Uint64
+
+	// The following are monotonic timestamps from monotonicNow, or zero
+	// if unset, and the last delay.
+	firstFailure atomic.Int64
+	successAt    atomic.Int64
+	lastDelay    atomic.Int64
+}
+
+// BackoffStats is a snapshot of the state of a [Backoff].
+type BackoffStats struct {
+	// Attempt is the number of calls to [Backoff.Next] since the last
+	// reset.
+	Attempt int
+
+	// Resets is the number of resets performed, whether by
+	// [Backoff.Reset] or because of ResetAfter.
+	Resets int
+
+	// LastDelay is the delay last returned by [Backoff.Next] since the
+	// last reset, or zero.
+	LastDelay time.Duration
+
+	// NextMin and NextMax are the bounds of the next delay. See
+	// [Backoff.Peek].
+	NextMin, NextMax time.Duration
+
+	// Elapsed is the time since the first call to [Backoff.Next] after the
+	// last reset, that is, since the first failure, or zero.
+	Elapsed time.Duration
+}
+
+// Next returns the delay to wait after the current attempt, drawn like
+// [Policy.Sleep] draws it, and advances to the next attempt. It ends the
+// success reported by [Backoff.Success], if any.
+func (b *Backoff) Next() time.Duration {
+	now := b.Policy.monotonic()
+	successAt := time.Duration(b.successAt.Swap(0))
+	expired := b.ResetAfter > 0 && successAt > 0 && now-successAt >= b.ResetAfter
+
+	var attempt uint32
+	for {
+		old := b.state.Load()
+		var resets uint32
+		attempt, resets = unpackBackoffState(old)
+		if expired {
+			attempt, resets = 0, resets+1
+		}
+		if b.state.CompareAndSwap(old, packBackoffState(min(attempt+1, math.MaxInt32), resets)) {
+			break
+		}
+	}
+	if attempt == 0 {
+		b.firstFailure.Store(int64(now))
+	}
+
+	var d time.Duration
+	if b.Decorrelated {
+		var prev int64
+		if attempt > 0 {
+			prev = b.lastDelay.Load()
+		}
+		lo, hi := decorrelatedBounds(int64(b.Policy.Base), int64(b.Policy.Cap), prev)
+		if b.Policy.Base > 0 && b.Policy.Cap > 0 {
+			d = time.Duration(lo + randN(b.Policy.Rand, hi-lo+1))
+		}
+	} else {
+		d = b.Policy.delay(context.Background(), int(attempt), nil)
+	}
+	b.lastDelay.Store(int64(d))
+	return d
+}
+
+// Peek returns the inclusive bounds of the delay the next call to
+// [Backoff.Next] will draw, before the adjustments of Policy.Slot and
+// Policy.Blackout, without advancing the attempt counter. It lets callers
+// decide whether to retry at all before committing to it.
+func (b *Backoff) Peek() (lo, hi time.Duration) {
+	attempt, _ := unpackBackoffState(b.state.Load())
+	if successAt := time.Duration(b.successAt.Load()); b.ResetAfter > 0 && successAt > 0 &&
+		b.Policy.monotonic()-successAt >= b.ResetAfter {
+		attempt = 0
+	}
+	if b.Decorrelated {
+		if b.Policy.Base <= 0 || b.Policy.Cap <= 0 {
+			return 0, 0
+		}
+		var prev int64
+		if attempt > 0 {
+			prev = b.lastDelay.Load()
+		}
+		l, h := decorrelatedBounds(int64(b.Policy.Base), int64(b.Policy.Cap), prev)
+		return time.Duration(l), time.Duration(h)
+	}
+	return b.Policy.DelayBounds(int(attempt))
+}
+
+// Attempt returns the number of calls to [Backoff.Next] since b was created
+// or last reset.
+func (b *Backoff) Attempt() int {
+	attempt, _ := unpackBackoffState(b.state.Load())
+	return int(attempt)
+}
+
+// Stats returns a snapshot of the state of b. Its fields are read one at a
+// time, so a snapshot taken during concurrent calls to [Backoff.Next] may mix
+// values from before and after them.
+func (b *Backoff) Stats() BackoffStats {
+	attempt, resets := unpackBackoffState(b.state.Load())
+	s := BackoffStats{Attempt: int(attempt), Resets: int(resets)}
+	if attempt > 0 {
+		s.LastDelay = time.Duration(b.lastDelay.Load())
+		if firstFailure := time.Duration(b.firstFailure.Load()); firstFailure > 0 {
+			s.Elapsed = max(b.Policy.monotonic()-firstFailure, 0)
+		}
+	}
+	s.NextMin, s.NextMax = b.Peek()
+	return s
+}
+
+// Success reports that the current attempt succeeded, such as a connection
+// being established, and starts the period that must last ResetAfter for the
+// attempt counter to be reset. Later calls before the next [Backoff.Next] do
+// not restart the period.
+func (b *Backoff) Success() {
+	b.successAt.CompareAndSwap(0, int64(b.Policy.monotonic()))
+}
+
+// Reset resets the attempt counter, so that the next delay is drawn as for
+// the first attempt again.
+func (b *Backoff) Reset() {
+	for {
+		old := b.state.Load()
+		_, resets := unpackBackoffState(old)
+		if b.state.CompareAndSwap(old, packBackoffState(0, resets+1)) {
+			break
+		}
+	}
+	b.successAt.Store(0)
+	b.firstFailure.Store(0)
+	b.lastDelay.Store(0)
+}
+
+// packBackoffState packs the attempt counter and the number of resets of a
+// [Backoff] into one word.
+func packBackoffState(attempt, resets uint32) uint64 {
+	return uint64(resets)<<32 | uint64(attempt)
+}
+
+// unpackBackoffState is the inverse of [packBackoffState].
+func unpackBackoffState(state uint64) (attempt, resets uint32) {
+	return uint32(state), uint32(state >> 32)
+}
@@ -0,0 +1,138 @@
+package backoff
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	t.Run("NextAndReset", func(t *testing.T) {
+		b := &Backoff{Policy: &Policy{Base: time.Second, Cap: time.Minute, Jitter: NoJitter}}
+		for i, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+			if got := b.Next(); got != want {
+				t.Errorf("got %v for attempt %d, want %v", got, i, want)
+			}
+		}
+		if got, want := b.Attempt(), 3; got != want {
+			t.Errorf("got attempt %d, want %d", got, want)
+		}
+
+		b.Reset()
+		if got, want := b.Attempt(), 0; got != want {
+			t.Errorf("got attempt %d, want %d", got, want)
+		}
+		if got, want := b.Next(), time.Second; got != want {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("ResetAfter", func(t *testing.T) {
+		b := &Backoff{
+			Policy:     &Policy{Base: time.Second, Cap: time.Minute, Jitter: NoJitter},
+			ResetAfter: 20 * time.Millisecond,
+		}
+		b.Next()
+		b.Next()
+
+		// A success that does not last keeps backing off.
+		b.Success()
+		if got, want := b.Next(), 4*time.Second; got != want {
+			t.Errorf("got %v, want %v", got, want)
+		}
+
+		// A success that lasts resets the counter.
+		b.Success()
+		time.Sleep(30 * time.Millisecond)
+		if got, want := b.Next(), time.Second; got != want {
+			t.Errorf("got %v, want %v", got, want)
+		}
+		if got, want := b.Attempt(), 1; got != want {
+			t.Errorf("got attempt %d, want %d", got, want)
+		}
+		if got, want := b.Stats().Resets, 1; got != want {
+			t.Errorf("got %d resets, want %d", got, want)
+		}
+
+		// Without a success, time alone does not reset the counter.
+		time.Sleep(30 * time.Millisecond)
+		if got, want := b.Next(), 2*time.Second; got != want {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Peek", func(t *testing.T) {
+		b := &Backoff{Policy: &Policy{Base: time.Second, Cap: time.Minute}}
+		b.Next()
+		for range 2 {
+			if lo, hi := b.Peek(); lo != 0 || hi != 2*time.Second-1 {
+				t.Errorf("got [%v, %v], want [0s, %v]", lo, hi, 2*time.Second-1)
+			}
+		}
+		if got, want := b.Attempt(), 1; got != want {
+			t.Errorf("got attempt %d, want %d", got, want)
+		}
+	})
+
+	t.Run("Stats", func(t *testing.T) {
+		b := &Backoff{Policy: &Policy{Base: time.Second, Cap: time.Minute, Jitter: NoJitter}}
+		if got, want := b.Stats(), (BackoffStats{NextMin: time.Second, NextMax: time.Second}); got != want {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+
+		b.Next()
+		b.Next()
+		time.Sleep(10 * time.Millisecond)
+		s := b.Stats()
+		if s.Attempt != 2 || s.Resets != 0 || s.LastDelay != 2*time.Second || s.NextMin != 4*time.Second || s.NextMax != 4*time.Second {
+			t.Errorf("got %+v, want attempt 2, no resets, last delay 2s and next delay 4s", s)
+		}
+		if s.Elapsed < 10*time.Millisecond {
+			t.Errorf("got elapsed %v, want at least %v", s.Elapsed, 10*time.Millisecond)
+		}
+
+		b.Reset()
+		if got, want := b.Stats(), (BackoffStats{Resets: 1, NextMin: time.Second, NextMax: time.Second}); got != want {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("Decorrelated", func(t *testing.T) {
+		b := &Backoff{Policy: &Policy{Base: 100 * time.Millisecond, Cap: time.Second}, Decorrelated: true}
+		prev := b.Policy.Base
+		for range 100 {
+			lo, hi := b.Peek()
+			if wantHi := min(b.Policy.Cap, 3*prev) - 1; lo != b.Policy.Base || hi != wantHi {
+				t.Fatalf("got bounds [%v, %v], want [%v, %v]", lo, hi, b.Policy.Base, wantHi)
+			}
+			d := b.Next()
+			if d < lo || d > hi {
+				t.Fatalf("got %v, want range [%v, %v]", d, lo, hi)
+			}
+			prev = d
+		}
+
+		b.Reset()
+		if lo, hi := b.Peek(); lo != b.Policy.Base || hi != 3*b.Policy.Base-1 {
+			t.Errorf("got bounds [%v, %v] after reset, want [%v, %v]", lo, hi, b.Policy.Base, 3*b.Policy.Base-1)
+		}
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		b := &Backoff{Policy: &Policy{Base: time.Nanosecond, Cap: time.Nanosecond}}
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for range 100 {
+					b.Next()
+				}
+			}()
+		}
+		wg.Wait()
+		if got, want := b.Attempt(), 1000; got != want {
+			t.Errorf("got attempt %d, want %d", got, want)
+		}
+	})
+}
@@ -0,0 +1,72 @@
+//go:build backoff_strict
+
+package backoff
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStrict(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		fn      func()
+		wantErr error
+	}{
+		{
+			name:    "DurationZeroBase",
+			fn:      func() { Duration(0, time.Second, 0) },
+			wantErr: ErrInvalidBase,
+		},
+		{
+			name:    "DurationNanosZeroCap",
+			fn:      func() { DurationNanos(1, 0, 0) },
+			wantErr: ErrInvalidCap,
+		},
+		{
+			name:    "FillNegativeAttempt",
+			fn:      func() { Fill(make([]time.Duration, 1), time.Millisecond, time.Second, -1) },
+			wantErr: ErrInvalidAttempt,
+		},
+		{
+			name:    "DurationFactorBelowOne",
+			fn:      func() { DurationFactor(time.Millisecond, time.Second, 0.5, 0) },
+			wantErr: ErrInvalidMultiplier,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				err, _ := recover().(error)
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("got %v, want %v", err, tt.wantErr)
+				}
+			}()
+			tt.fn()
+		})
+	}
+
+	t.Run("DurationAtAttempt", func(t *testing.T) {
+		for _, attempt := range []float64{math.NaN(), math.Inf(-1), -0.5} {
+			func() {
+				defer func() {
+					err, _ := recover().(error)
+					if !errors.Is(err, ErrInvalidAttempt) {
+						t.Errorf("got %v, want %v", err, ErrInvalidAttempt)
+					}
+					if want := fmt.Sprint(attempt); err == nil || !strings.HasSuffix(err.Error(), want) {
+						t.Errorf("got %v, want it to report %s", err, want)
+					}
+				}()
+				DurationAt(time.Millisecond, time.Second, attempt)
+			}()
+		}
+	})
+
+	t.Run("ValidParameters", func(t *testing.T) {
+		Duration(time.Millisecond, time.Second, 1)
+	})
+}
@@ -0,0 +1,113 @@
+package backoff
+
+import (
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// throttleBuckets is the number of buckets the sliding window of an
+// [AdaptiveThrottle] is divided into.
+const throttleBuckets = 10
+
+// ErrThrottled is returned by [Policy.Retry] when its Throttle declines an
+// attempt before it is sent.
+var ErrThrottled = errors.New("backoff: attempt declined by adaptive throttling")
+
+// AdaptiveThrottle throttles attempts on the client side while an upstream is
+// rejecting them, as described in the "Handling Overload" chapter of the Site
+// Reliability Engineering book. It counts the attempts made and those the
+// upstream accepted, that is, did not throttle, over a sliding window, and
+// declines a new attempt with probability
+//
+//	max(0, (attempts - K*accepted) / (attempts + 1))
+//
+// so that, once the upstream throttles more than it accepts, attempts are shed
+// before being sent instead of adding to its load. Declined attempts count as
+// attempts, which keeps shedding as long as the upstream stays overloaded, while
+// the attempts still let through find out when it recovers.
+//
+// Set it as [Policy.Throttle] to throttle the attempts of [Policy.Retry] and
+// of the helpers built on it, or call [AdaptiveThrottle.Allow] and
+// [AdaptiveThrottle.Record] around each attempt. Share one AdaptiveThrottle per
+// upstream.
+//
+// An AdaptiveThrottle is safe for concurrent use. The zero value is ready to
+// use.
+type AdaptiveThrottle struct {
+	// Window is the length of the sliding window. Zero means 2 minutes.
+	Window time.Duration
+
+	// K is how many attempts are let through per accepted one before
+	// attempts start to be declined. Lower values shed more aggressively.
+	// Zero means 2.
+	K float64
+
+	mu      sync.Mutex
+	buckets [throttleBuckets]throttleBucket
+}
+
+// throttleBucket counts the attempts recorded by an [AdaptiveThrottle] in one
+// slice of its window.
+type throttleBucket struct {
+	start    time.Time
+	attempts int
+	accepted int
+}
+
+// Allow counts an attempt and reports whether it should be sent, or declined
+// because the upstream is throttling.
+func (t *AdaptiveThrottle) Allow() bool {
+	k := t.K
+	if k <= 0 {
+		k = 2
+	}
+	now := time.Now()
+	oldest := now.Add(-t.window())
+
+	t.mu.Lock()
+	var attempts, accepted int
+	for _, b := range t.buckets {
+		if b.start.After(oldest) {
+			attempts += b.attempts
+			accepted += b.accepted
+		}
+	}
+	t.bucket(now).attempts++
+	t.mu.Unlock()
+
+	reject := (float64(attempts) - k*float64(accepted)) / float64(attempts+1)
+	return reject <= 0 || rand.Float64() >= reject
+}
+
+// Record records the outcome of an attempt that was sent. The attempt counts
+// as accepted unless [ClassifyError] classifies err as [ClassThrottled].
+func (t *AdaptiveThrottle) Record(err error) {
+	if err != nil && ClassifyError(err) == ClassThrottled {
+		return
+	}
+	t.mu.Lock()
+	t.bucket(time.Now()).accepted++
+	t.mu.Unlock()
+}
+
+// bucket returns the bucket for now, starting it over if it holds counts from
+// an earlier slice of the window. t.mu must be held.
+func (t *AdaptiveThrottle) bucket(now time.Time) *throttleBucket {
+	width := max(t.window()/throttleBuckets, 1)
+	start := now.Truncate(width)
+	b := &t.buckets[start.UnixNano()/int64(width)%throttleBuckets]
+	if !b.start.Equal(start) {
+		*b = throttleBucket{start: start}
+	}
+	return b
+}
+
+// window returns the effective length of the sliding window.
+func (t *AdaptiveThrottle) window() time.Duration {
+	if t.Window > 0 {
+		return t.Window
+	}
+	return 2 * time.Minute
+}
@@ -0,0 +1,69 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAdaptiveThrottle(t *testing.T) {
+	t.Run("AllowsWhileAccepted", func(t *testing.T) {
+		var th AdaptiveThrottle
+		for i := range 1000 {
+			if !th.Allow() {
+				t.Fatalf("got declined attempt %d, want allowed", i)
+			}
+			th.Record(errors.New("boom"))
+		}
+	})
+
+	t.Run("ShedsWhileThrottled", func(t *testing.T) {
+		var th AdaptiveThrottle
+		for range 1000 {
+			if th.Allow() {
+				th.Record(throttledError{})
+			}
+		}
+		declined := 0
+		for range 100 {
+			if !th.Allow() {
+				declined++
+			}
+		}
+		if declined < 90 {
+			t.Errorf("got %d declined attempts, want at least 90", declined)
+		}
+	})
+
+	t.Run("RecoversAfterWindow", func(t *testing.T) {
+		th := AdaptiveThrottle{Window: 10 * time.Millisecond}
+		for range 1000 {
+			th.Allow()
+		}
+		time.Sleep(20 * time.Millisecond)
+		if !th.Allow() {
+			t.Error("got declined, want allowed")
+		}
+	})
+}
+
+func TestPolicyThrottle(t *testing.T) {
+	th := &AdaptiveThrottle{}
+	for range 100000 {
+		th.Allow()
+	}
+	p := &Policy{Base: time.Millisecond, Cap: time.Millisecond, MaxAttempts: 3, Throttle: th}
+
+	var calls int
+	err := p.Retry(context.Background(), func(context.Context) error {
+		calls++
+		return nil
+	})
+	if !errors.Is(err, ErrThrottled) {
+		t.Errorf("got %v, want %v", err, ErrThrottled)
+	}
+	if calls != 0 {
+		t.Errorf("got %d calls, want 0", calls)
+	}
+}
@@ -0,0 +1,35 @@
+package backoff
+
+import (
+	"context"
+	"iter"
+	"time"
+)
+
+// ScheduleTimes returns an iterator that yields the absolute times at which
+// the attempts under p should fire, starting with start for the first attempt
+// and adding the delay that [Policy.Attempts] would wait for each subsequent
+// one, without waiting. The delay is computed as of the previous time rather
+// than now, so p.Slot and p.Blackout apply to the scheduled times, but it is
+// not clamped to the deadline of ctx. It suits external schedulers and
+// cron-like systems that store timestamps rather than durations.
+//
+// It stops after p.MaxAttempts times, before the first time more than
+// p.MaxElapsedTime after start, before the first time past the deadline of
+// ctx, when ctx is done, or when the consumer breaks.
+func ScheduleTimes(ctx context.Context, start time.Time, p *Policy) iter.Seq[time.Time] {
+	return func(yield func(time.Time) bool) {
+		deadline, hasDeadline := ctx.Deadline()
+		t := start
+		for attempt := 0; p.MaxAttempts <= 0 || attempt < p.MaxAttempts; attempt++ {
+			if ctx.Err() != nil || (hasDeadline && t.After(deadline)) ||
+				(p.MaxElapsedTime > 0 && t.Sub(start) > p.MaxElapsedTime) {
+				return
+			}
+			if !yield(t) {
+				return
+			}
+			t = t.Add(p.unclampedDelayAt(attempt, 0, t))
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package backoff
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestScheduleTimes(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("CumulativeDelays", func(t *testing.T) {
+		p := &Policy{Base: time.Second, Cap: time.Minute, MaxAttempts: 6, Replay: []time.Duration{1, 2, 3, 4, 5}}
+		var got []time.Time
+		for at := range ScheduleTimes(context.Background(), start, p) {
+			got = append(got, at)
+		}
+		want := []time.Duration{0, 1, 3, 6, 10, 15}
+		if len(got) != len(want) {
+			t.Fatalf("got %d times, want %d", len(got), len(want))
+		}
+		for i := range want {
+			if wantTime := start.Add(want[i]); !got[i].Equal(wantTime) {
+				t.Errorf("got %v for attempt %d, want %v", got[i], i, wantTime)
+			}
+		}
+	})
+
+	t.Run("Blackout", func(t *testing.T) {
+		blackoutStart, blackoutEnd := start.Add(2*time.Second), start.Add(time.Minute)
+		p := &Policy{
+			Base:        time.Second,
+			Cap:         time.Second,
+			MaxAttempts: 4,
+			Replay:      []time.Duration{time.Second, time.Second, time.Second},
+			Blackout: func(t time.Time) (time.Time, bool) {
+				return blackoutEnd, !t.Before(blackoutStart) && t.Before(blackoutEnd)
+			},
+		}
+		var got []time.Time
+		for at := range ScheduleTimes(context.Background(), start, p) {
+			got = append(got, at)
+		}
+		if len(got) != 4 {
+			t.Fatalf("got %d times, want 4", len(got))
+		}
+		if want := start.Add(time.Second); !got[1].Equal(want) {
+			t.Errorf("got %v, want %v", got[1], want)
+		}
+		if got[2].Before(blackoutEnd) || !got[2].Before(blackoutEnd.Add(p.Cap)) {
+			t.Errorf("got %v, want within [%v, %v)", got[2], blackoutEnd, blackoutEnd.Add(p.Cap))
+		}
+		if want := got[2].Add(time.Second); !got[3].Equal(want) {
+			t.Errorf("got %v, want %v", got[3], want)
+		}
+	})
+
+	t.Run("Slot", func(t *testing.T) {
+		p := &Policy{Base: time.Second, Cap: time.Second, MaxAttempts: 3, Replay: []time.Duration{time.Second, time.Second}, Slot: 10 * time.Second}
+		var got []time.Time
+		for at := range ScheduleTimes(context.Background(), start, p) {
+			got = append(got, at)
+		}
+		want := []time.Duration{0, 10 * time.Second, 20 * time.Second}
+		if len(got) != len(want) {
+			t.Fatalf("got %d times, want %d", len(got), len(want))
+		}
+		for i := range want {
+			if wantTime := start.Add(want[i]); !got[i].Equal(wantTime) {
+				t.Errorf("got %v for attempt %d, want %v", got[i], i, wantTime)
+			}
+		}
+	})
+
+	t.Run("NonDecreasing", func(t *testing.T) {
+		p := &Policy{Base: time.Second, Cap: time.Minute, MaxAttempts: 20}
+		prev := start
+		for at := range ScheduleTimes(context.Background(), start, p) {
+			if at.Before(prev) {
+				t.Errorf("got %v, want >= %v", at, prev)
+			}
+			prev = at
+		}
+	})
+
+	t.Run("StopsAtDeadline", func(t *testing.T) {
+		now := time.Now()
+		ctx, cancel := context.WithDeadline(context.Background(), now.Add(3*time.Second+time.Millisecond))
+		t.Cleanup(cancel)
+
+		p := &Policy{Base: time.Second, Cap: time.Second, Replay: []time.Duration{time.Second, time.Second, time.Second, time.Second, time.Second}}
+		var got int
+		for range ScheduleTimes(ctx, now, p) {
+			got++
+		}
+		if want := 4; got != want {
+			t.Errorf("got %d times, want %d", got, want)
+		}
+	})
+
+	t.Run("StopsAtMaxElapsedTime", func(t *testing.T) {
+		p := &Policy{Base: time.Second, Cap: time.Second, Jitter: NoJitter, MaxElapsedTime: 3 * time.Second}
+		var got []time.Time
+		for tm := range ScheduleTimes(context.Background(), start, p) {
+			if got = append(got, tm); len(got) > 10 {
+				break
+			}
+		}
+		if len(got) != 4 {
+			t.Fatalf("got %d times, want 4", len(got))
+		}
+		if last := got[len(got)-1]; !last.Equal(start.Add(3 * time.Second)) {
+			t.Errorf("got %v, want %v", last, start.Add(3*time.Second))
+		}
+	})
+
+	t.Run("Unlimited", func(t *testing.T) {
+		p := &Policy{Base: time.Second, Cap: time.Minute}
+		var got int
+		for range ScheduleTimes(context.Background(), start, p) {
+			if got++; got == 100 {
+				break
+			}
+		}
+		if got != 100 {
+			t.Errorf("got %d times, want 100", got)
+		}
+	})
+}
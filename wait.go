@@ -0,0 +1,93 @@
+package backoff
+
+import (
+	"context"
+	"runtime"
+	"time"
+)
+
+// Waiter waits out backoff delays on behalf of a [Policy].
+type Waiter interface {
+	// Wait blocks for d, or until ctx is done, in which case it returns
+	// ctx.Err().
+	Wait(ctx context.Context, d time.Duration) error
+}
+
+// HybridWaiter is a [Waiter] for microsecond-scale backoff. Delays shorter
+// than Threshold are waited out by repeatedly yielding the processor with
+// [runtime.Gosched], which avoids the granularity of the runtime timer at the
+// cost of keeping a processor busy. Longer delays use a timer.
+type HybridWaiter struct {
+	Threshold time.Duration
+}
+
+// Wait implements [Waiter].
+func (w HybridWaiter) Wait(ctx context.Context, d time.Duration) error {
+	if d >= w.Threshold {
+		return sleep(ctx, d)
+	}
+
+	deadline := time.Now().Add(d)
+	for time.Now().Before(deadline) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		runtime.Gosched()
+	}
+	return nil
+}
+
+// sleep blocks for d, or until ctx is done, in which case it returns
+// ctx.Err().
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// timerWaiter is the default [Waiter] of [Policy.Attempts]. It reuses a single
+// runtime timer across waits.
+type timerWaiter struct {
+	timer *time.Timer
+}
+
+// Wait implements [Waiter].
+func (w *timerWaiter) Wait(ctx context.Context, d time.Duration) error {
+	if w.timer == nil {
+		w.timer = time.NewTimer(d)
+	} else {
+		w.timer.Reset(d)
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-w.timer.C:
+		return nil
+	}
+}
+
+// stop stops the underlying timer, if any.
+func (w *timerWaiter) stop() {
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+}
+
+// WaitUntil blocks until attempt n of p is due, that is, for the delays after
+// attempts 0 through n-1 in turn, or until ctx is done, in which case it
+// returns ctx.Err(). It suits components that merely need to wait out a known
+// number of steps of a schedule run elsewhere.
+func WaitUntil(ctx context.Context, p *Policy, n int) error {
+	for attempt := range max(n, 0) {
+		if err := p.Sleep(ctx, attempt); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,126 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHybridWaiter(t *testing.T) {
+	t.Run("BelowThreshold", func(t *testing.T) {
+		w := HybridWaiter{Threshold: time.Millisecond}
+		d := 50 * time.Microsecond
+
+		startTime := time.Now()
+		if err := w.Wait(context.Background(), d); err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		if elapsed := time.Since(startTime); elapsed < d {
+			t.Errorf("got %v, want >= %v", elapsed, d)
+		}
+	})
+
+	t.Run("AboveThreshold", func(t *testing.T) {
+		w := HybridWaiter{Threshold: time.Microsecond}
+		d := 2 * time.Millisecond
+
+		startTime := time.Now()
+		if err := w.Wait(context.Background(), d); err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		if elapsed := time.Since(startTime); elapsed < d {
+			t.Errorf("got %v, want >= %v", elapsed, d)
+		}
+	})
+
+	t.Run("ContextCanceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		w := HybridWaiter{Threshold: time.Hour}
+		if err := w.Wait(ctx, time.Minute); !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want %v", err, context.Canceled)
+		}
+	})
+}
+
+func TestTimerWaiter(t *testing.T) {
+	t.Run("ReusesTimer", func(t *testing.T) {
+		var w timerWaiter
+		defer w.stop()
+
+		if err := w.Wait(context.Background(), time.Nanosecond); err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		timer := w.timer
+		if err := w.Wait(context.Background(), time.Nanosecond); err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		if w.timer != timer {
+			t.Error("got new timer, want reused timer")
+		}
+	})
+
+	t.Run("StopsPendingTimer", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		var w timerWaiter
+		if err := w.Wait(ctx, time.Hour); !errors.Is(err, context.Canceled) {
+			t.Fatalf("got %v, want %v", err, context.Canceled)
+		}
+		w.stop()
+		if w.timer.Stop() {
+			t.Error("got active timer, want stopped timer")
+		}
+		select {
+		case <-w.timer.C:
+			t.Error("got stale value, want drained channel")
+		default:
+		}
+	})
+}
+
+func TestWaitUntil(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		n         int
+		wantWaits int
+	}{
+		{
+			name:      "Zero",
+			n:         0,
+			wantWaits: 0,
+		},
+		{
+			name:      "Negative",
+			n:         -1,
+			wantWaits: 0,
+		},
+		{
+			name:      "Several",
+			n:         5,
+			wantWaits: 5,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var w recordingWaiter
+			p := &Policy{Base: time.Second, Cap: time.Second, Waiter: &w}
+			if err := WaitUntil(context.Background(), p, tt.n); err != nil {
+				t.Fatalf("got %v, want nil", err)
+			}
+			if got := len(w.delays); got != tt.wantWaits {
+				t.Errorf("got %d waits, want %d", got, tt.wantWaits)
+			}
+		})
+	}
+
+	t.Run("ContextCanceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		p := &Policy{Base: time.Hour, Cap: time.Hour}
+		if err := WaitUntil(ctx, p, 3); !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want %v", err, context.Canceled)
+		}
+	})
+}
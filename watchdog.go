@@ -0,0 +1,175 @@
+package backoff
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Watchdog raises alerts when the retries of an operation exceed thresholds,
+// so that teams can page on "we have been retrying X for 10 minutes" without
+// custom metric queries. It watches the [RetryEvent] values of the retry
+// sequences it observes, see [Watchdog.Observe].
+//
+// A Watchdog is safe for concurrent use. It must not be copied after first
+// use.
+type Watchdog struct {
+	// MaxAttempts, if positive, raises an [AlertAttempts] once a retry
+	// sequence has failed MaxAttempts attempts and still retries.
+	MaxAttempts int
+
+	// MaxElapsed, if positive, raises an [AlertElapsed] once a retry
+	// sequence has still been retrying MaxElapsed after its first attempt
+	// started.
+	MaxElapsed time.Duration
+
+	// MaxRetries, if positive, raises an [AlertRate] once the sequences of
+	// a key have retried more than MaxRetries times within Window.
+	MaxRetries int
+
+	// Window is the period over which MaxRetries is counted. Zero means
+	// one minute.
+	Window time.Duration
+
+	// Alert is called with every alert. It must not be nil.
+	Alert func(WatchdogAlert)
+
+	mu    sync.Mutex
+	rates map[string]*watchdogRate
+}
+
+// watchdogRate counts the retries of a key of a [Watchdog] in the current
+// window.
+type watchdogRate struct {
+	windowStart time.Duration
+	retries     int
+	alerted     bool
+}
+
+// WatchdogAlert describes an alert raised by a [Watchdog].
+type WatchdogAlert struct {
+	// Key is the key of the operation.
+	Key string
+
+	// Reason is the threshold that was exceeded.
+	Reason AlertReason
+
+	// Attempt is the zero-based attempt that raised the alert.
+	Attempt int
+
+	// Elapsed is how long the retry sequence has been going on.
+	Elapsed time.Duration
+
+	// Retries is the number of retries of the key in the current window.
+	Retries int
+
+	// Err is the error of the attempt that raised the alert.
+	Err error
+}
+
+// AlertReason is the threshold of a [Watchdog] behind a [WatchdogAlert].
+type AlertReason int
+
+// The alert reasons.
+const (
+	// AlertAttempts means that a retry sequence exceeded MaxAttempts.
+	AlertAttempts AlertReason = iota
+
+	// AlertElapsed means that a retry sequence exceeded MaxElapsed.
+	AlertElapsed
+
+	// AlertRate means that the retries of a key exceeded MaxRetries
+	// within Window.
+	AlertRate
+)
+
+// String returns the name of r.
+func (r AlertReason) String() string {
+	switch r {
+	case AlertAttempts:
+		return "attempts"
+	case AlertElapsed:
+		return "elapsed"
+	case AlertRate:
+		return "rate"
+	}
+	return "AlertReason(" + strconv.Itoa(int(r)) + ")"
+}
+
+// Observe returns a function for [Policy.Observe] that watches one retry
+// sequence of the operation identified by key, such as an operation name.
+// Call Observe for every sequence, as the returned function tracks the
+// attempts and elapsed time of a single one, raising each of AlertAttempts
+// and AlertElapsed at most once for it. AlertRate is counted across all the
+// sequences of key and raised at most once per window, so keys should come
+// from a bounded set.
+func (w *Watchdog) Observe(key string) func(e RetryEvent) {
+	var (
+		mu                              sync.Mutex
+		start                           time.Duration
+		attemptsAlerted, elapsedAlerted bool
+	)
+	return func(e RetryEvent) {
+		now := monotonicNow()
+		mu.Lock()
+		if e.Attempt == 0 || start == 0 {
+			start = now - e.Took
+		}
+		elapsed := now - start
+		if e.Outcome != OutcomeRetry {
+			mu.Unlock()
+			return
+		}
+		var reasons []AlertReason
+		if w.MaxAttempts > 0 && e.Attempt+1 >= w.MaxAttempts && !attemptsAlerted {
+			attemptsAlerted = true
+			reasons = append(reasons, AlertAttempts)
+		}
+		if w.MaxElapsed > 0 && elapsed >= w.MaxElapsed && !elapsedAlerted {
+			elapsedAlerted = true
+			reasons = append(reasons, AlertElapsed)
+		}
+		mu.Unlock()
+
+		retries, rateExceeded := w.countRetry(key, now)
+		if rateExceeded {
+			reasons = append(reasons, AlertRate)
+		}
+		for _, reason := range reasons {
+			w.Alert(WatchdogAlert{
+				Key:     key,
+				Reason:  reason,
+				Attempt: e.Attempt,
+				Elapsed: elapsed,
+				Retries: retries,
+				Err:     e.Err,
+			})
+		}
+	}
+}
+
+// countRetry counts a retry of key at now and returns the number of retries
+// of key in the current window, and whether they just exceeded MaxRetries.
+func (w *Watchdog) countRetry(key string, now time.Duration) (int, bool) {
+	window := w.Window
+	if window <= 0 {
+		window = time.Minute
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.rates == nil {
+		w.rates = map[string]*watchdogRate{}
+	}
+	r := w.rates[key]
+	if r == nil || now-r.windowStart >= window {
+		r = &watchdogRate{windowStart: now}
+		w.rates[key] = r
+	}
+	r.retries++
+	if w.MaxRetries > 0 && r.retries > w.MaxRetries && !r.alerted {
+		r.alerted = true
+		return r.retries, true
+	}
+	return r.retries, false
+}
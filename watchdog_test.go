@@ -0,0 +1,92 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestWatchdog(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	t.Run("Attempts", func(t *testing.T) {
+		c := newFakeClock(t)
+		var alerts []WatchdogAlert
+		w := &Watchdog{MaxAttempts: 2, Alert: func(a WatchdogAlert) { alerts = append(alerts, a) }}
+		p := &Policy{Base: time.Second, Cap: time.Second, Jitter: NoJitter, MaxAttempts: 5, Waiter: c, Observe: w.Observe("fetch")}
+		p.Retry(context.Background(), func(context.Context) error { return errFailed })
+
+		if len(alerts) != 1 {
+			t.Fatalf("got %d alerts, want 1", len(alerts))
+		}
+		if got, want := alerts[0], (WatchdogAlert{Key: "fetch", Reason: AlertAttempts, Attempt: 1, Elapsed: time.Second, Retries: 2, Err: errFailed}); got != want {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("Elapsed", func(t *testing.T) {
+		c := newFakeClock(t)
+		var alerts []WatchdogAlert
+		w := &Watchdog{MaxElapsed: 10 * time.Minute, Alert: func(a WatchdogAlert) { alerts = append(alerts, a) }}
+		p := &Policy{Base: 4 * time.Minute, Cap: 4 * time.Minute, Jitter: NoJitter, MaxAttempts: 6, Waiter: c, Observe: w.Observe("fetch")}
+		p.Retry(context.Background(), func(context.Context) error { return errFailed })
+
+		if len(alerts) != 1 {
+			t.Fatalf("got %d alerts, want 1", len(alerts))
+		}
+		if got, want := alerts[0].Reason, AlertElapsed; got != want {
+			t.Errorf("got %v, want %v", got, want)
+		}
+		if got, want := alerts[0].Elapsed, 12*time.Minute; got != want {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Rate", func(t *testing.T) {
+		var alerts []WatchdogAlert
+		w := &Watchdog{MaxRetries: 3, Alert: func(a WatchdogAlert) { alerts = append(alerts, a) }}
+		for range 3 {
+			p := &Policy{Base: time.Second, Cap: time.Second, MaxAttempts: 3, Waiter: &recordingWaiter{}, Observe: w.Observe("fetch")}
+			p.Retry(context.Background(), func(context.Context) error { return errFailed })
+		}
+
+		var reasons []AlertReason
+		for _, a := range alerts {
+			reasons = append(reasons, a.Reason)
+		}
+		if want := []AlertReason{AlertRate}; !slices.Equal(reasons, want) {
+			t.Fatalf("got %v, want %v", reasons, want)
+		}
+		if got, want := alerts[0].Retries, 4; got != want {
+			t.Errorf("got %d retries, want %d", got, want)
+		}
+	})
+
+	t.Run("NoAlertOnSuccess", func(t *testing.T) {
+		var alerts int
+		w := &Watchdog{MaxAttempts: 1, MaxRetries: 1, Alert: func(WatchdogAlert) { alerts++ }}
+		p := &Policy{Base: time.Second, Cap: time.Second, Observe: w.Observe("fetch")}
+		p.Retry(context.Background(), func(context.Context) error { return nil })
+		if alerts != 0 {
+			t.Errorf("got %d alerts, want 0", alerts)
+		}
+	})
+}
+
+func TestAlertReasonString(t *testing.T) {
+	for _, tt := range []struct {
+		r    AlertReason
+		want string
+	}{
+		{AlertAttempts, "attempts"},
+		{AlertElapsed, "elapsed"},
+		{AlertRate, "rate"},
+		{AlertReason(7), "AlertReason(7)"},
+	} {
+		if got := tt.r.String(); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
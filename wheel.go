@@ -0,0 +1,139 @@
+package backoff
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// wheelSlots is the number of slots of a [TimerWheel].
+const wheelSlots = 512
+
+// TimerWheel is a [Waiter] for workloads with tens of thousands of concurrent
+// waits. Instead of arming one runtime timer per wait, it files every wait
+// into a slot of a hashed timer wheel driven by a single ticker, so all waits
+// due in the same tick are woken together. The ticker only runs while there
+// are waits pending.
+//
+// Delays are rounded to whole ticks, so a wait lasts at least d and less than
+// d plus two ticks. Share a single TimerWheel across the policies of a process
+// by setting it as their Waiter.
+//
+// A TimerWheel is safe for concurrent use. The zero value is ready to use.
+type TimerWheel struct {
+	// Tick is the granularity of the wheel. Zero means 10 milliseconds.
+	Tick time.Duration
+
+	mu      sync.Mutex
+	slots   [][]*wheelEntry
+	cursor  int
+	pending int
+	running bool
+}
+
+// wheelEntry is a wait filed into a [TimerWheel].
+type wheelEntry struct {
+	rounds   int64
+	done     chan struct{}
+	canceled bool
+}
+
+// Wait implements [Waiter].
+func (w *TimerWheel) Wait(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	tick := w.tick()
+
+	// The next tick may be due at any moment, so one more tick than d
+	// covers is needed to wait at least d.
+	// Computed this way, the tick count cannot overflow even for delays
+	// near math.MaxInt64.
+	ticks := int64(d / tick)
+	if d%tick != 0 {
+		ticks++
+	}
+	ticks = min(ticks, math.MaxInt64-1) + 1
+	e := &wheelEntry{rounds: (ticks - 1) / wheelSlots, done: make(chan struct{})}
+
+	w.mu.Lock()
+	if w.slots == nil {
+		w.slots = make([][]*wheelEntry, wheelSlots)
+	}
+	slot := (w.cursor + int(ticks%wheelSlots)) % wheelSlots
+	w.slots[slot] = append(w.slots[slot], e)
+	w.pending++
+	if !w.running {
+		w.running = true
+		go w.run(tick)
+	}
+	w.mu.Unlock()
+
+	select {
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		select {
+		case <-e.done:
+		default:
+			e.canceled = true
+			w.pending--
+		}
+		return ctx.Err()
+	}
+}
+
+// run turns the wheel every tick until no waits are pending.
+func (w *TimerWheel) run(tick time.Duration) {
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	for range ticker.C {
+		if !w.advance() {
+			return
+		}
+	}
+}
+
+// advance turns the wheel by one slot, waking the waits that are due, and
+// reports whether any waits are still pending.
+func (w *TimerWheel) advance() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.cursor = (w.cursor + 1) % wheelSlots
+	entries := w.slots[w.cursor]
+	kept := entries[:0]
+	for _, e := range entries {
+		switch {
+		case e.canceled:
+		case e.rounds > 0:
+			e.rounds--
+			kept = append(kept, e)
+		default:
+			close(e.done)
+			w.pending--
+		}
+	}
+	clear(entries[len(kept):])
+	w.slots[w.cursor] = kept
+
+	if w.pending == 0 {
+		// Only canceled waits can be left, so drop them along with the
+		// ticker.
+		clear(w.slots)
+		w.running = false
+		return false
+	}
+	return true
+}
+
+// tick returns the effective granularity of w.
+func (w *TimerWheel) tick() time.Duration {
+	if w.Tick > 0 {
+		return w.Tick
+	}
+	return 10 * time.Millisecond
+}
@@ -0,0 +1,98 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTimerWheel(t *testing.T) {
+	t.Run("WaitsAtLeastDelay", func(t *testing.T) {
+		w := &TimerWheel{Tick: time.Millisecond}
+		for _, d := range []time.Duration{time.Nanosecond, time.Millisecond, 5 * time.Millisecond} {
+			startTime := time.Now()
+			if err := w.Wait(context.Background(), d); err != nil {
+				t.Fatalf("got %v, want nil", err)
+			}
+			if elapsed := time.Since(startTime); elapsed < d {
+				t.Errorf("got %v, want >= %v", elapsed, d)
+			}
+		}
+	})
+
+	t.Run("MoreRoundsThanSlots", func(t *testing.T) {
+		w := &TimerWheel{Tick: time.Microsecond}
+		d := 2 * wheelSlots * w.Tick
+		startTime := time.Now()
+		if err := w.Wait(context.Background(), d); err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		if elapsed := time.Since(startTime); elapsed < d {
+			t.Errorf("got %v, want >= %v", elapsed, d)
+		}
+	})
+
+	t.Run("HugeDelay", func(t *testing.T) {
+		for _, w := range []*TimerWheel{{}, {Tick: time.Nanosecond}} {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+			if err := w.Wait(ctx, math.MaxInt64); !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("got %v, want %v", err, context.DeadlineExceeded)
+			}
+			cancel()
+		}
+	})
+
+	t.Run("ContextCanceled", func(t *testing.T) {
+		var w TimerWheel
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(5*time.Millisecond, cancel)
+		if err := w.Wait(ctx, time.Hour); !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want %v", err, context.Canceled)
+		}
+
+		// The wheel stops turning once the canceled wait is dropped.
+		deadline := time.Now().Add(time.Second)
+		for {
+			w.mu.Lock()
+			running := w.running
+			w.mu.Unlock()
+			if !running {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("got running wheel, want stopped")
+			}
+			time.Sleep(w.tick())
+		}
+	})
+
+	t.Run("ConcurrentWaits", func(t *testing.T) {
+		w := &TimerWheel{Tick: time.Millisecond}
+		var wg sync.WaitGroup
+		for i := range 1000 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := w.Wait(context.Background(), time.Duration(i%20)*time.Millisecond); err != nil {
+					t.Errorf("got %v, want nil", err)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("AsPolicyWaiter", func(t *testing.T) {
+		p := &Policy{Base: time.Millisecond, Cap: time.Millisecond, MaxAttempts: 3, Waiter: &TimerWheel{Tick: time.Millisecond}}
+		var calls int
+		p.Retry(context.Background(), func(context.Context) error {
+			calls++
+			return errors.New("failed")
+		})
+		if calls != 3 {
+			t.Errorf("got %d calls, want 3", calls)
+		}
+	})
+}